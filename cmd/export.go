@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/saleh-ghazimoradi/FilmFetch/config"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/exporter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <path>",
+	Short: "Export the movie catalogue to an ndjson, csv or json file",
+	Long: `Export writes every movie matching the filters to path. The format is taken
+from the file extension unless --format is given, and a path ending in .gz
+is gzipped. The file only appears once the export has completed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+		path := args[0]
+		flags := cmd.Flags()
+
+		name := strings.ToLower(path)
+		compress := strings.HasSuffix(name, ".gz")
+
+		format, _ := flags.GetString("format")
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(name, ".gz")), ".")
+			if format == "jsonl" {
+				format = exporter.FormatNDJSON
+			}
+		}
+
+		query := &dto.QueryMovie{}
+		query.Title, _ = flags.GetString("title")
+		query.Genres, _ = flags.GetStringSlice("genres")
+		query.YearFrom, _ = flags.GetInt("year-from")
+		query.YearTo, _ = flags.GetInt("year-to")
+		query.Filters.Sort, _ = flags.GetString("sort")
+		query.Filters.SortSafeList = dto.MovieSortSafeList
+
+		v := validator.NewValidator()
+		dto.ValidateExportMovie(v, query, format)
+		if !v.Valid() {
+			logger.Error("invalid export options", "errors", v.Errors)
+			os.Exit(1)
+		}
+
+		cfg, err := config.NewConfig()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		db, err := connectPostgresql(cfg)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.Error(err.Error())
+			}
+		}()
+
+		movieService, genreService := catalogueServices(cfg, db)
+
+		catalogue, err := genreService.Catalogue(context.Background())
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		dto.NormalizeGenres(query.Genres, catalogue)
+
+		count, err := exportMovies(movieService.ExportMovies, query, path, format, compress)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		logger.Info("export finished", "path", path, "format", format, "movies", count)
+	},
+}
+
+// exportMovies writes the export to a temporary file next to path and only
+// renames it into place once it is complete.
+func exportMovies(export func(context.Context, *dto.QueryMovie, func(*domain.Movie) error) error, query *dto.QueryMovie, path, format string, compress bool) (count int, err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	var body io.Writer = file
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(file)
+		body = gz
+	}
+
+	out, err := exporter.NewWriter(body, format)
+	if err != nil {
+		return 0, err
+	}
+
+	err = export(context.Background(), query, func(movie *domain.Movie) error {
+		count++
+		return out.Write(movie)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err = out.Close(); err != nil {
+		return 0, err
+	}
+
+	if gz != nil {
+		if err = gz.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = file.Close(); err != nil {
+		return 0, err
+	}
+
+	if err = os.Chmod(file.Name(), 0o644); err != nil {
+		return 0, err
+	}
+
+	return count, os.Rename(file.Name(), path)
+}
+
+func init() {
+	exportCmd.Flags().String("format", "", "ndjson, csv or json, taken from the file extension when omitted")
+	exportCmd.Flags().String("title", "", "only export movies whose title matches")
+	exportCmd.Flags().StringSlice("genres", nil, "only export movies with all of these genres")
+	exportCmd.Flags().Int("year-from", 0, "only export movies released in or after this year")
+	exportCmd.Flags().Int("year-to", 0, "only export movies released in or before this year")
+	exportCmd.Flags().String("sort", "id", "sort order, as for GET /v1/movies")
+	rootCmd.AddCommand(exportCmd)
+}
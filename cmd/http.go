@@ -1,18 +1,22 @@
 package cmd
 
 import (
+	"crypto/rand"
 	"fmt"
 	"github.com/saleh-ghazimoradi/FilmFetch/config"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/routes"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/importer"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/server"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
 	"github.com/saleh-ghazimoradi/FilmFetch/utils"
 	"github.com/saleh-ghazimoradi/FilmFetch/utils/email"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils/storage"
 	"github.com/wneessen/go-mail"
+	"golang.org/x/time/rate"
 	"log/slog"
 	"os"
 	"time"
@@ -46,6 +50,7 @@ var httpCmd = &cobra.Command{
 			utils.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
 			utils.WithSSLMode(cfg.Postgresql.SSLMode),
 			utils.WithTimeout(cfg.Postgresql.Timeout),
+			utils.WithReplicas(cfg.Postgresql.ReplicaDSNs...),
 		)
 
 		db, err := postgresql.Connect()
@@ -61,6 +66,23 @@ var httpCmd = &cobra.Command{
 			}
 		}()
 
+		replicas, err := postgresql.ConnectReplicas()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		defer func() {
+			for _, replica := range replicas {
+				if err := replica.Close(); err != nil {
+					logger.Error(err.Error())
+				}
+			}
+		}()
+
+		dbWrite := repository.NewPrimary(db)
+		dbRead := repository.NewReplicaSet(db, replicas, cfg.Postgresql.ReplicaCheckInterval)
+
 		clientMail, err := mail.NewClient(
 			cfg.Mail.Host,
 			mail.WithSMTPAuth(mail.SMTPAuthLogin),
@@ -84,23 +106,84 @@ var httpCmd = &cobra.Command{
 		)
 
 		customError := helper.NewCustomErr(logger)
-		middleWare := middleware.NewMiddleware(cfg, customError)
 
 		healthHandler := handlers.NewHealthHandler(cfg, logger, customError)
 		healthRoutes := routes.NewHealthRoute(healthHandler)
 
-		movieRepository := repository.NewMovieRepository(db, db)
-		movieService := service.NewMovieService(movieRepository)
-		movieHandler := handlers.NewMovieHandler(logger, customError, movieService)
-		movieRoutes := routes.NewMovieRoutes(movieHandler)
+		userRepository := repository.NewUserRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		tokenRepository := repository.NewTokenRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		permissionRepository := repository.NewPermissionRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		listRepository := repository.NewListRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		txManager := repository.NewTxManager(db)
+		tokenService := service.NewTokenService(txManager, tokenRepository, userRepository)
+		userService := service.NewUserService(txManager, userRepository, tokenRepository, permissionRepository, listRepository)
+
+		middleWare := middleware.NewMiddleware(cfg, customError, tokenRepository, permissionRepository)
 
-		userRepository := repository.NewUserRepository(db, db)
-		tokenRepository := repository.NewTokenRepository(db, db)
-		tokenService := service.NewTokenService(tokenRepository, userRepository)
-		userService := service.NewUserService(userRepository)
 		userHandler := handlers.NewUserHandler(customError, userService, tokenService, mailer, logger)
+		userRoutes := routes.NewUserRoutes(userHandler, middleWare)
+
+		// An email is only forgotten once its bucket has had time to refill,
+		// otherwise eviction would reset the limit early.
+		activationRefill := cfg.RateLimiter.ActivationResendEvery * time.Duration(cfg.RateLimiter.ActivationResendBurst)
+		activationLimiter := helper.NewRateLimiter(rate.Every(cfg.RateLimiter.ActivationResendEvery), cfg.RateLimiter.ActivationResendBurst, max(time.Hour, activationRefill))
+		tokenHandler := handlers.NewTokenHandler(customError, userService, tokenService, mailer, activationLimiter, logger)
+		tokenRoutes := routes.NewTokenRoutes(tokenHandler)
+
+		movieRepository := repository.NewMovieRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout, cfg.Postgresql.SuggestTimeout, cfg.Postgresql.ExportTimeout)
+		similarCache := service.NewSimilarCache(cfg.Application.SimilarCacheTTL, cfg.Application.SimilarCacheSize)
+		movieService := service.NewMovieService(txManager, movieRepository, listRepository, similarCache)
+		cursorSecret := []byte(cfg.Application.CursorSecret)
+		if len(cursorSecret) == 0 {
+			logger.Warn("CURSOR_SECRET is not set, pagination cursors will not survive a restart")
+			cursorSecret = []byte(rand.Text())
+		}
+
+		genreRepository := repository.NewGenreRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		genreService := service.NewGenreService(txManager, genreRepository, cfg.Application.GenreCacheTTL, similarCache)
+		genreHandler := handlers.NewGenreHandler(logger, customError, genreService)
+		genreRoutes := routes.NewGenreRoutes(genreHandler, middleWare)
 
-		userRoutes := routes.NewUserRoutes(userHandler)
+		cursorCodec := helper.NewCursorCodec(cursorSecret)
+
+		movieHandler := handlers.NewMovieHandler(logger, customError, movieService, genreService, cursorCodec, cfg.Postgresql.ExportTimeout)
+		movieRoutes := routes.NewMovieRoutes(movieHandler, middleWare)
+
+		personRepository := repository.NewPersonRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		creditRepository := repository.NewCreditRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		personService := service.NewPersonService(personRepository)
+		creditService := service.NewCreditService(creditRepository, movieRepository, personRepository)
+		personHandler := handlers.NewPersonHandler(logger, customError, personService)
+		creditHandler := handlers.NewCreditHandler(logger, customError, creditService)
+		personRoutes := routes.NewPersonRoutes(personHandler, creditHandler, middleWare)
+
+		reviewRepository := repository.NewReviewRepository(dbWrite, dbRead, cfg.Postgresql.QueryTimeout)
+		reviewService := service.NewReviewService(txManager, reviewRepository, movieRepository, similarCache)
+		reviewHandler := handlers.NewReviewHandler(logger, customError, reviewService)
+		reviewRoutes := routes.NewReviewRoutes(reviewHandler, middleWare)
+
+		listService := service.NewListService(txManager, listRepository, movieRepository)
+		listHandler := handlers.NewListHandler(logger, customError, listService, cursorCodec)
+		listRoutes := routes.NewListRoutes(listHandler, middleWare)
+
+		blobStore, err := storage.NewLocalStore(cfg.Storage.Root, cfg.Storage.BaseURL)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		artworkService := service.NewArtworkService(movieRepository, blobStore, similarCache)
+		artworkHandler := handlers.NewArtworkHandler(logger, customError, artworkService, cfg.Storage.MaxUploadSize)
+		artworkRoutes := routes.NewArtworkRoutes(artworkHandler, middleWare, blobStore.Handler())
+
+		tmdb := importer.NewTMDB(
+			importer.WithBaseURL(cfg.TMDB.BaseURL),
+			importer.WithAPIKey(cfg.TMDB.APIKey),
+			importer.WithTimeout(cfg.TMDB.Timeout),
+		)
+		movieImporter := importer.NewImporter(movieService, genreService, map[string]importer.MetadataProvider{"tmdb": tmdb})
+		importHandler := handlers.NewImportHandler(logger, customError, movieImporter, cfg.Application.BulkMaxBodySize)
+		importRoutes := routes.NewImportRoutes(importHandler, middleWare, customError)
 
 		registerRoutes := routes.NewRegister(
 			routes.WithCustomError(customError),
@@ -108,6 +191,13 @@ var httpCmd = &cobra.Command{
 			routes.WithHealthRoutes(healthRoutes),
 			routes.WithMovieRoutes(movieRoutes),
 			routes.WithUserRoutes(userRoutes),
+			routes.WithTokenRoutes(tokenRoutes),
+			routes.WithGenreRoutes(genreRoutes),
+			routes.WithPersonRoutes(personRoutes),
+			routes.WithReviewRoutes(reviewRoutes),
+			routes.WithListRoutes(listRoutes),
+			routes.WithArtworkRoutes(artworkRoutes),
+			routes.WithImportRoutes(importRoutes),
 		)
 
 		httpServer := server.NewServer(
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/config"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/importer"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd groups the commands that bring movies in from outside.
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import movies into the catalogue",
+}
+
+// importTMDBCmd represents the import tmdb command
+var importTMDBCmd = &cobra.Command{
+	Use:   "tmdb",
+	Short: "Import movies from TMDB by their TMDB or IMDb id",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+		ids, err := cmd.Flags().GetStringSlice("id")
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		if len(ids) == 0 {
+			logger.Error("at least one --id must be given")
+			os.Exit(1)
+		}
+
+		cfg, err := config.NewConfig()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		db, err := connectPostgresql(cfg)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.Error(err.Error())
+			}
+		}()
+
+		movieService, genreService := catalogueServices(cfg, db)
+
+		tmdb := importer.NewTMDB(
+			importer.WithBaseURL(cfg.TMDB.BaseURL),
+			importer.WithAPIKey(cfg.TMDB.APIKey),
+			importer.WithTimeout(cfg.TMDB.Timeout),
+		)
+		movieImporter := importer.NewImporter(movieService, genreService, map[string]importer.MetadataProvider{"tmdb": tmdb})
+
+		failed := 0
+		for _, id := range ids {
+			result, err := movieImporter.Import(context.Background(), "tmdb", id)
+			if err != nil {
+				var invalidMetadata *importer.InvalidMetadataError
+				switch {
+				case errors.As(err, &invalidMetadata):
+					logger.Error("import failed", "id", id, "error", err.Error(), "fields", invalidMetadata.Errors)
+				default:
+					logger.Error("import failed", "id", id, "error", err.Error())
+				}
+				failed++
+				continue
+			}
+
+			logger.Info("movie imported", "id", id, "movie_id", result.Movie.Id, "title", result.Movie.Title, "created", result.Created, "skipped_genres", result.SkippedGenres)
+		}
+
+		if failed > 0 {
+			logger.Error("import finished with errors", "failed", failed, "total", len(ids))
+			os.Exit(1)
+		}
+	},
+}
+
+// importFileCmd represents the import file command
+var importFileCmd = &cobra.Command{
+	Use:   "file <path>",
+	Short: "Import movies from a CSV or NDJSON file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+		path := args[0]
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".csv":
+				format = importer.FormatCSV
+			case ".ndjson", ".jsonl":
+				format = importer.FormatNDJSON
+			default:
+				logger.Error("cannot tell the format from the file name, pass --format csv or --format ndjson")
+				os.Exit(1)
+			}
+		}
+
+		atomic, err := cmd.Flags().GetBool("atomic")
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		cfg, err := config.NewConfig()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		db, err := connectPostgresql(cfg)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.Error(err.Error())
+			}
+		}()
+
+		movieService, genreService := catalogueServices(cfg, db)
+		movieImporter := importer.NewImporter(movieService, genreService, nil)
+
+		result, err := movieImporter.ImportFile(context.Background(), file, format, atomic)
+		if err != nil && !errors.Is(err, importer.ErrIncomplete) {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		for _, lineError := range result.Errors {
+			logger.Error("invalid row", "line", lineError.Line, "fields", lineError.Errors)
+		}
+
+		logger.Info("import finished", "created", len(result.Created), "failed", len(result.Errors))
+
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		if len(result.Errors) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// connectPostgresql opens the primary database for the commands that work
+// on the catalogue directly.
+func connectPostgresql(cfg *config.Config) (*sql.DB, error) {
+	postgresql := utils.NewPostgresql(
+		utils.WithHost(cfg.Postgresql.Host),
+		utils.WithPort(cfg.Postgresql.Port),
+		utils.WithUser(cfg.Postgresql.User),
+		utils.WithPassword(cfg.Postgresql.Password),
+		utils.WithName(cfg.Postgresql.Name),
+		utils.WithMaxOpenConn(cfg.Postgresql.MaxOpenConn),
+		utils.WithMaxIdleConn(cfg.Postgresql.MaxIdleConn),
+		utils.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
+		utils.WithSSLMode(cfg.Postgresql.SSLMode),
+		utils.WithTimeout(cfg.Postgresql.Timeout),
+	)
+	return postgresql.Connect()
+}
+
+// catalogueServices wires the movie and genre services against db alone,
+// without replicas.
+func catalogueServices(cfg *config.Config, db *sql.DB) (service.MovieService, service.GenreService) {
+	dbWrite := repository.NewPrimary(db)
+
+	txManager := repository.NewTxManager(db)
+	movieRepository := repository.NewMovieRepository(dbWrite, dbWrite, cfg.Postgresql.QueryTimeout, cfg.Postgresql.SuggestTimeout, cfg.Postgresql.ExportTimeout)
+	listRepository := repository.NewListRepository(dbWrite, dbWrite, cfg.Postgresql.QueryTimeout)
+	genreRepository := repository.NewGenreRepository(dbWrite, dbWrite, cfg.Postgresql.QueryTimeout)
+
+	similarCache := service.NewSimilarCache(cfg.Application.SimilarCacheTTL, cfg.Application.SimilarCacheSize)
+	movieService := service.NewMovieService(txManager, movieRepository, listRepository, similarCache)
+	genreService := service.NewGenreService(txManager, genreRepository, cfg.Application.GenreCacheTTL, similarCache)
+
+	return movieService, genreService
+}
+
+func init() {
+	importTMDBCmd.Flags().StringSlice("id", nil, "TMDB id or IMDb id (tt...) of a movie to import, may be repeated")
+	importCmd.AddCommand(importTMDBCmd)
+
+	importFileCmd.Flags().String("format", "", "csv or ndjson, taken from the file extension when omitted")
+	importFileCmd.Flags().Bool("atomic", false, "import nothing unless every row is valid")
+	importCmd.AddCommand(importFileCmd)
+	rootCmd.AddCommand(importCmd)
+}
@@ -1,14 +1,25 @@
 package config
 
+import "time"
+
 type Application struct {
-	Version     string `env:"VERSION"`
-	Environment string `env:"ENVIRONMENT"`
+	Version       string        `env:"VERSION"`
+	Environment   string        `env:"ENVIRONMENT"`
+	CursorSecret  string        `env:"CURSOR_SECRET"`
+	GenreCacheTTL time.Duration `env:"GENRE_CACHE_TTL" envDefault:"1m"`
+
+	SimilarCacheTTL  time.Duration `env:"SIMILAR_CACHE_TTL" envDefault:"10m"`
+	SimilarCacheSize int           `env:"SIMILAR_CACHE_SIZE" envDefault:"1000"`
+
+	BulkMaxBodySize int64 `env:"BULK_MAX_BODY_SIZE" envDefault:"33554432"`
 }
 
 type RateLimiter struct {
-	RPS     float64 `env:"RPS"`
-	Burst   int     `env:"BURST"`
-	Enabled bool    `env:"ENABLED"`
+	RPS                   float64       `env:"RPS"`
+	Burst                 int           `env:"BURST"`
+	Enabled               bool          `env:"ENABLED"`
+	ActivationResendEvery time.Duration `env:"ACTIVATION_RESEND_EVERY" envDefault:"5m"`
+	ActivationResendBurst int           `env:"ACTIVATION_RESEND_BURST" envDefault:"1"`
 }
 
 type Mail struct {
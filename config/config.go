@@ -17,6 +17,8 @@ type Config struct {
 	Application Application
 	RateLimiter RateLimiter
 	Mail        Mail
+	Storage     Storage
+	TMDB        TMDB
 }
 
 func NewConfig() (*Config, error) {
@@ -3,14 +3,20 @@ package config
 import "time"
 
 type Postgresql struct {
-	Host        string        `env:"POSTGRES_HOST"`
-	Port        string        `env:"POSTGRES_PORT"`
-	User        string        `env:"POSTGRES_USER"`
-	Password    string        `env:"POSTGRES_PASSWORD"`
-	Name        string        `env:"POSTGRES_NAME"`
-	MaxOpenConn int           `env:"POSTGRES_MAX_OPEN_CONN"`
-	MaxIdleConn int           `env:"POSTGRES_MAX_IDLE_CONN"`
-	MaxIdleTime time.Duration `env:"POSTGRES_MAX_IDLE_TIME"`
-	SSLMode     string        `env:"POSTGRES_SSL_MODE"`
-	Timeout     time.Duration `env:"POSTGRES_TIMEOUT"`
+	Host           string        `env:"POSTGRES_HOST"`
+	Port           string        `env:"POSTGRES_PORT"`
+	User           string        `env:"POSTGRES_USER"`
+	Password       string        `env:"POSTGRES_PASSWORD"`
+	Name           string        `env:"POSTGRES_NAME"`
+	MaxOpenConn    int           `env:"POSTGRES_MAX_OPEN_CONN"`
+	MaxIdleConn    int           `env:"POSTGRES_MAX_IDLE_CONN"`
+	MaxIdleTime    time.Duration `env:"POSTGRES_MAX_IDLE_TIME"`
+	SSLMode        string        `env:"POSTGRES_SSL_MODE"`
+	Timeout        time.Duration `env:"POSTGRES_TIMEOUT"`
+	QueryTimeout   time.Duration `env:"POSTGRES_QUERY_TIMEOUT" envDefault:"3s"`
+	SuggestTimeout time.Duration `env:"POSTGRES_SUGGEST_TIMEOUT" envDefault:"300ms"`
+	ExportTimeout  time.Duration `env:"POSTGRES_EXPORT_TIMEOUT" envDefault:"10m"`
+
+	ReplicaDSNs          []string      `env:"POSTGRES_REPLICA_DSNS" envSeparator:","`
+	ReplicaCheckInterval time.Duration `env:"POSTGRES_REPLICA_CHECK_INTERVAL" envDefault:"5s"`
 }
@@ -0,0 +1,7 @@
+package config
+
+type Storage struct {
+	Root          string `env:"STORAGE_ROOT" envDefault:"./uploads"`
+	BaseURL       string `env:"STORAGE_BASE_URL" envDefault:"/static/"`
+	MaxUploadSize int64  `env:"STORAGE_MAX_UPLOAD_SIZE" envDefault:"10485760"`
+}
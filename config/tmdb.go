@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type TMDB struct {
+	BaseURL string        `env:"TMDB_BASE_URL" envDefault:"https://api.themoviedb.org/3"`
+	APIKey  string        `env:"TMDB_API_KEY"`
+	Timeout time.Duration `env:"TMDB_TIMEOUT" envDefault:"10s"`
+}
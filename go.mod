@@ -11,6 +11,7 @@ require (
 	github.com/tomasen/realip v0.0.0-20180522021738-f0c99a92ddce
 	github.com/wneessen/go-mail v0.7.2
 	golang.org/x/crypto v0.43.0
+	golang.org/x/image v0.25.0
 	golang.org/x/time v0.14.0
 )
 
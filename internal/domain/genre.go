@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const PermissionGenresWrite = "genres:write"
+
+type Genre struct {
+	Id        int64     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
+	Version   int32     `json:"version"`
+}
+
+// GenreCatalogue resolves whatever a client calls a genre (its slug, display
+// name or one of its aliases, in any case) to the canonical slug.
+type GenreCatalogue struct {
+	slugs map[string]string
+}
+
+func (c *GenreCatalogue) Resolve(value string) (string, bool) {
+	slug, ok := c.slugs[strings.ToLower(strings.TrimSpace(value))]
+	return slug, ok
+}
+
+func NewGenreCatalogue(genres []*Genre) *GenreCatalogue {
+	c := &GenreCatalogue{slugs: make(map[string]string)}
+	for _, genre := range genres {
+		c.slugs[strings.ToLower(genre.Name)] = genre.Slug
+		for _, alias := range genre.Aliases {
+			c.slugs[strings.ToLower(alias)] = genre.Slug
+		}
+	}
+	// Slugs are added last so an alias can never shadow another genre's slug.
+	for _, genre := range genres {
+		c.slugs[genre.Slug] = genre.Slug
+	}
+	return c
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Every user has one watchlist and one watched list, created with the
+// account. Any number of custom lists can be added next to them.
+const (
+	ListWatchlist = "watchlist"
+	ListWatched   = "watched"
+	ListCustom    = "custom"
+)
+
+type List struct {
+	Id        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserId    int64     `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Public    bool      `json:"public"`
+	Position  int32     `json:"position"`
+	Version   int32     `json:"version"`
+}
+
+func (l *List) IsBuiltIn() bool {
+	return l.Kind != ListCustom
+}
+
+type ListItem struct {
+	Position  int32      `json:"position"`
+	AddedAt   time.Time  `json:"added_at"`
+	WatchedAt *time.Time `json:"watched_at,omitempty"`
+	Movie     *Movie     `json:"movie"`
+}
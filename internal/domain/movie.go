@@ -1,15 +1,75 @@
 package domain
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// Movie carries the average and number of its review ratings. Both are
+// maintained by the review writes rather than set through the movie itself.
+// ImdbId and TmdbId identify the movie at IMDb and TMDB; either may be empty.
 type Movie struct {
-	Id        int64     `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	Title     string    `json:"title"`
-	Year      int32     `json:"year,omitzero"`
-	Runtime   int32     `json:"runtime,omitzero"`
-	Genres    []string  `json:"genres,omitzero"`
-	Version   int32     `json:"version"`
+	Id          int64     `json:"id"`
+	CreatedAt   time.Time `json:"-"`
+	Title       string    `json:"title"`
+	Year        int32     `json:"year,omitzero"`
+	Runtime     int32     `json:"runtime,omitzero"`
+	Genres      []string  `json:"genres,omitzero"`
+	Rating      float64   `json:"rating,omitzero"`
+	RatingCount int32     `json:"rating_count,omitzero"`
+	Poster      Artwork   `json:"poster,omitempty"`
+	Backdrop    Artwork   `json:"backdrop,omitempty"`
+	ImdbId      string    `json:"imdb_id,omitzero"`
+	TmdbId      int64     `json:"tmdb_id,omitzero"`
+	Version     int32     `json:"version"`
+}
+
+const (
+	ArtworkPoster   = "poster"
+	ArtworkBackdrop = "backdrop"
+)
+
+// ArtworkVariants lists the widths each kind of artwork is resized to.
+var ArtworkVariants = map[string]map[string]int{
+	ArtworkPoster:   {"small": 185, "medium": 342, "large": 780},
+	ArtworkBackdrop: {"small": 300, "medium": 780, "large": 1280},
+}
+
+// Artwork maps a variant name to the URL it is served from. It is stored as
+// a jsonb column.
+type Artwork map[string]string
+
+func (a *Artwork) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, a)
+	case string:
+		return json.Unmarshal([]byte(src), a)
+	default:
+		return fmt.Errorf("cannot scan %T into Artwork", src)
+	}
+}
+
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type Facets map[string][]FacetBucket
+
+// SimilarMovie is a movie ranked by how alike it is to another one. Score
+// runs from 0 (nothing in common) to 1.
+type SimilarMovie struct {
+	Movie *Movie  `json:"movie"`
+	Score float64 `json:"score"`
+}
+
+type MovieSuggestion struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	Year  int32  `json:"year,omitzero"`
 }
@@ -0,0 +1,15 @@
+package domain
+
+import "slices"
+
+const (
+	PermissionMoviesRead   = "movies:read"
+	PermissionMoviesWrite  = "movies:write"
+	PermissionMoviesImport = "movies:import"
+)
+
+type Permissions []string
+
+func (p Permissions) Include(code string) bool {
+	return slices.Contains(p, code)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	RoleDirector = "director"
+	RoleWriter   = "writer"
+	RoleActor    = "actor"
+)
+
+type Person struct {
+	Id        int64     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	Name      string    `json:"name"`
+	BirthYear int32     `json:"birth_year,omitzero"`
+	Biography string    `json:"biography,omitzero"`
+	Version   int32     `json:"version"`
+}
+
+// Credit links a person to a movie in a role. When credits are listed for a
+// movie the person's name is filled in; when they are listed for a person
+// (a filmography) the movie's title and year are.
+type Credit struct {
+	Id           int64  `json:"id"`
+	MovieId      int64  `json:"movie_id"`
+	PersonId     int64  `json:"person_id"`
+	Role         string `json:"role"`
+	Character    string `json:"character,omitzero"`
+	BillingOrder int32  `json:"billing_order"`
+	PersonName   string `json:"person_name,omitzero"`
+	MovieTitle   string `json:"movie_title,omitzero"`
+	MovieYear    int32  `json:"movie_year,omitzero"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type Review struct {
+	Id        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	MovieId   int64     `json:"movie_id"`
+	UserId    int64     `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	Rating    int32     `json:"rating"`
+	Body      string    `json:"body,omitzero"`
+	Version   int32     `json:"version"`
+}
@@ -3,13 +3,16 @@ package domain
 import "time"
 
 const (
-	ScopeActivation = "activation"
+	ScopeActivation     = "activation"
+	ScopeAuthentication = "authentication"
+	ScopePasswordReset  = "password-reset"
+	ScopeEmailChange    = "email-change"
 )
 
 type Token struct {
-	Plaintext string    `json:"plaintext"`
-	Hash      []byte    `json:"hash"`
-	UserId    int64     `json:"user_id"`
+	Plaintext string    `json:"token"`
+	Hash      []byte    `json:"-"`
+	UserId    int64     `json:"-"`
 	Expiry    time.Time `json:"expiry"`
-	Scope     string    `json:"scope"`
+	Scope     string    `json:"-"`
 }
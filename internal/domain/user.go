@@ -6,14 +6,21 @@ import (
 	"time"
 )
 
+var AnonymousUser = &User{}
+
 type User struct {
-	Id        int64     `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  Password  `json:"-"`
-	Activated bool      `json:"activated"`
-	Version   int       `json:"version"`
+	Id           int64     `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	PendingEmail *string   `json:"pending_email,omitempty"`
+	Password     Password  `json:"-"`
+	Activated    bool      `json:"activated"`
+	Version      int       `json:"version"`
+}
+
+func (u *User) IsAnonymous() bool {
+	return u == AnonymousUser
 }
 
 type Password struct {
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"regexp"
+)
+
+var SlugRX = regexp.MustCompile("^[a-z0-9]+(?:-[a-z0-9]+)*$")
+
+type Genre struct {
+	Slug    string   `json:"slug"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+type UpdateGenre struct {
+	Slug    *string  `json:"slug"`
+	Name    *string  `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// MergeGenre names the genre to fold into the one being merged into.
+type MergeGenre struct {
+	GenreId int64 `json:"genre_id"`
+}
+
+func validateSlug(v *validator.Validator, slug string) {
+	v.Check(slug != "", "slug", "must be provided")
+	v.Check(len(slug) <= 100, "slug", "must not be more than 100 bytes long")
+	v.Check(validator.Matches(slug, SlugRX), "slug", "must only contain lower case letters, digits and single dashes")
+}
+
+func validateGenreName(v *validator.Validator, name string) {
+	v.Check(name != "", "name", "must be provided")
+	v.Check(len(name) <= 100, "name", "must not be more than 100 bytes long")
+}
+
+func validateAliases(v *validator.Validator, aliases []string) {
+	v.Check(len(aliases) <= 20, "aliases", "must not contain more than 20 aliases")
+	v.Check(validator.Unique(aliases), "aliases", "must not contain duplicate values")
+	for _, alias := range aliases {
+		v.Check(alias != "", "aliases", "must not contain empty values")
+		v.Check(len(alias) <= 100, "aliases", "must not contain values more than 100 bytes long")
+	}
+}
+
+func ValidateGenre(v *validator.Validator, genre *Genre) {
+	validateSlug(v, genre.Slug)
+	validateGenreName(v, genre.Name)
+	validateAliases(v, genre.Aliases)
+}
+
+func ValidateUpdateGenre(v *validator.Validator, update *UpdateGenre) {
+	if update.Slug != nil {
+		validateSlug(v, *update.Slug)
+	}
+
+	if update.Name != nil {
+		validateGenreName(v, *update.Name)
+	}
+
+	if update.Aliases != nil {
+		validateAliases(v, update.Aliases)
+	}
+}
+
+func ValidateMergeGenre(v *validator.Validator, id int64, merge *MergeGenre) {
+	v.Check(merge.GenreId != 0, "genre_id", "must be provided")
+	v.Check(merge.GenreId >= 0, "genre_id", "must be a positive integer")
+	v.Check(merge.GenreId != id, "genre_id", "must not be the genre being merged into")
+}
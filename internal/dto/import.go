@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+
+type ImportMovie struct {
+	Provider string `json:"provider"`
+	Id       string `json:"id"`
+}
+
+func ValidateImportMovie(v *validator.Validator, input *ImportMovie, providers []string) {
+	v.Check(input.Provider != "", "provider", "must be provided")
+	v.Check(validator.PermittedValue(input.Provider, providers...), "provider", "must be a known provider")
+	v.Check(input.Id != "", "id", "must be provided")
+	v.Check(len(input.Id) <= 100, "id", "must not be more than 100 bytes long")
+}
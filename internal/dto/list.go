@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"strconv"
+	"time"
+)
+
+type List struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+type UpdateList struct {
+	Name     *string `json:"name"`
+	Public   *bool   `json:"public"`
+	Position *int32  `json:"position"`
+}
+
+// ListItem adds a movie to a list. A zero Position appends it; WatchedAt
+// only applies to the watched list and defaults to the time it is added.
+type ListItem struct {
+	MovieId   int64      `json:"movie_id"`
+	Position  int32      `json:"position"`
+	WatchedAt *time.Time `json:"watched_at"`
+}
+
+type UpdateListItem struct {
+	Position  *int32     `json:"position"`
+	WatchedAt *time.Time `json:"watched_at"`
+}
+
+type QueryListItems struct {
+	ListId  int64
+	Filters Filters
+}
+
+// NewListItemCursor points at a list item. Items are always kept in position
+// order, so the position is the only sort value.
+func NewListItemCursor(item *domain.ListItem, before bool) Cursor {
+	return Cursor{
+		Sort:   "position",
+		Value:  strconv.Itoa(int(item.Position)),
+		Id:     item.Movie.Id,
+		Before: before,
+	}
+}
+
+func validateListName(v *validator.Validator, name string) {
+	v.Check(name != "", "name", "must be provided")
+	v.Check(len(name) <= 100, "name", "must not be more than 100 bytes long")
+}
+
+func validatePosition(v *validator.Validator, position int32) {
+	v.Check(position >= 1, "position", "must be greater than zero")
+}
+
+func validateWatchedAt(v *validator.Validator, watchedAt time.Time) {
+	v.Check(!watchedAt.After(time.Now()), "watched_at", "must not be in the future")
+}
+
+func ValidateList(v *validator.Validator, list *List) {
+	validateListName(v, list.Name)
+}
+
+func ValidateUpdateList(v *validator.Validator, update *UpdateList) {
+	if update.Name != nil {
+		validateListName(v, *update.Name)
+	}
+
+	if update.Position != nil {
+		validatePosition(v, *update.Position)
+	}
+}
+
+func ValidateListItem(v *validator.Validator, item *ListItem) {
+	v.Check(item.MovieId > 0, "movie_id", "must be provided")
+
+	if item.Position != 0 {
+		validatePosition(v, item.Position)
+	}
+
+	if item.WatchedAt != nil {
+		validateWatchedAt(v, *item.WatchedAt)
+	}
+}
+
+func ValidateUpdateListItem(v *validator.Validator, update *UpdateListItem) {
+	if update.Position != nil {
+		validatePosition(v, *update.Position)
+	}
+
+	if update.WatchedAt != nil {
+		validateWatchedAt(v, *update.WatchedAt)
+	}
+}
@@ -1,8 +1,15 @@
 package dto
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,6 +19,8 @@ type Movie struct {
 	Year    int32    `json:"year"`
 	Runtime int32    `json:"runtime"`
 	Genres  []string `json:"genres"`
+	ImdbId  string   `json:"imdb_id"`
+	TmdbId  int64    `json:"tmdb_id"`
 }
 
 type UpdateMovie struct {
@@ -19,12 +28,33 @@ type UpdateMovie struct {
 	Year    *int32   `json:"year"`
 	Runtime *int32   `json:"runtime"`
 	Genres  []string `json:"genres"`
+	ImdbId  *string  `json:"imdb_id"`
+	TmdbId  *int64   `json:"tmdb_id"`
 }
 
+var ImdbIdRX = regexp.MustCompile(`^tt\d{7,10}$`)
+
+// MovieSortSafeList holds the sort values accepted wherever movies are
+// listed.
+var MovieSortSafeList = []string{"id", "title", "year", "runtime", "rating", "relevance", "-id", "-title", "-year", "-runtime", "-rating"}
+
 type QueryMovie struct {
-	Title   string
-	Genres  []string
-	Filters Filters
+	Title         string
+	Genres        []string
+	GenresAny     bool
+	ExcludeGenres []string
+	YearFrom      int
+	YearTo        int
+	RuntimeMin    int
+	RuntimeMax    int
+	PersonId      int
+	Facets        []string
+	Filters       Filters
+}
+
+type SuggestMovie struct {
+	Query string
+	Limit int
 }
 
 type Filters struct {
@@ -32,6 +62,53 @@ type Filters struct {
 	PageSize     int
 	Sort         string
 	SortSafeList []string
+	UseCursor    bool
+	Cursor       *Cursor
+	IncludeTotal bool
+}
+
+// Cursor points at the movie a keyset page starts after (or, when Before is
+// set, ends before). Value is the sort column of that movie in text form and
+// Id breaks ties between movies sharing it.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Value  string `json:"v"`
+	Id     int64  `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+func NewCursor(movie *domain.Movie, sort string, before bool) Cursor {
+	var value string
+	switch strings.TrimPrefix(sort, "-") {
+	case "title":
+		value = movie.Title
+	case "year":
+		value = strconv.Itoa(int(movie.Year))
+	case "runtime":
+		value = strconv.Itoa(int(movie.Runtime))
+	case "rating":
+		value = strconv.FormatFloat(movie.Rating, 'f', -1, 64)
+	default:
+		value = strconv.FormatInt(movie.Id, 10)
+	}
+
+	return Cursor{
+		Sort:   sort,
+		Value:  value,
+		Id:     movie.Id,
+		Before: before,
+	}
+}
+
+// NewSimilarCursor points at a movie in a similar movies ranking, which is
+// ordered by score and then id.
+func NewSimilarCursor(similar *domain.SimilarMovie, before bool) Cursor {
+	return Cursor{
+		Sort:   "score",
+		Value:  strconv.FormatFloat(similar.Score, 'g', -1, 64),
+		Id:     similar.Movie.Id,
+		Before: before,
+	}
 }
 
 func (f *Filters) Limit() int {
@@ -58,11 +135,15 @@ func (f *Filters) SortDirection() string {
 }
 
 type Metadata struct {
-	CurrentPage  int `json:"current_page,omitzero"`
-	PageSize     int `json:"page_size,omitzero"`
-	FirstPage    int `json:"first_page,omitzero"`
-	LastPage     int `json:"last_page,omitzero"`
-	TotalRecords int `json:"total_records,omitzero"`
+	CurrentPage  int    `json:"current_page,omitzero"`
+	PageSize     int    `json:"page_size,omitzero"`
+	FirstPage    int    `json:"first_page,omitzero"`
+	LastPage     int    `json:"last_page,omitzero"`
+	TotalRecords int    `json:"total_records,omitzero"`
+	NextCursor   string `json:"next_cursor,omitzero"`
+	PrevCursor   string `json:"prev_cursor,omitzero"`
+	HasNext      bool   `json:"-"`
+	HasPrev      bool   `json:"-"`
 }
 
 func CalculateMetadata(totalRecords, page, pageSize int) Metadata {
@@ -79,7 +160,31 @@ func CalculateMetadata(totalRecords, page, pageSize int) Metadata {
 	}
 }
 
-func ValidateMovie(v *validator.Validator, movie *Movie) {
+// NormalizeGenres replaces every genre the catalogue knows with its canonical
+// slug, in place, and returns the ones it doesn't know.
+func NormalizeGenres(genres []string, catalogue *domain.GenreCatalogue) []string {
+	var unknown []string
+	for i, genre := range genres {
+		slug, ok := catalogue.Resolve(genre)
+		if !ok {
+			unknown = append(unknown, genre)
+			continue
+		}
+		genres[i] = slug
+	}
+	return unknown
+}
+
+func validateGenres(v *validator.Validator, genres []string, catalogue *domain.GenreCatalogue) {
+	if unknown := NormalizeGenres(genres, catalogue); len(unknown) > 0 {
+		v.AddError("genres", fmt.Sprintf("must only contain known genres, unknown: %s", strings.Join(unknown, ", ")))
+	}
+	v.Check(len(genres) >= 1, "genres", "must contain at least 1 genre")
+	v.Check(len(genres) <= 5, "genres", "must not contain more than 5 genres")
+	v.Check(validator.Unique(genres), "genres", "must not contain duplicate values")
+}
+
+func ValidateMovie(v *validator.Validator, movie *Movie, catalogue *domain.GenreCatalogue) {
 	v.Check(movie.Title != "", "title", "must be provided")
 	v.Check(len(movie.Title) <= 500, "title", "must not be more than 500 bytes long")
 
@@ -91,12 +196,18 @@ func ValidateMovie(v *validator.Validator, movie *Movie) {
 	v.Check(movie.Runtime > 0, "runtime", "must be a positive integer")
 
 	v.Check(movie.Genres != nil, "genres", "must be provided")
-	v.Check(len(movie.Genres) >= 1, "genres", "must contain at least 1 genre")
-	v.Check(len(movie.Genres) <= 5, "genres", "must not contain more than 5 genres")
-	v.Check(validator.Unique(movie.Genres), "genres", "must not contain duplicate values")
+	validateGenres(v, movie.Genres, catalogue)
+
+	if movie.ImdbId != "" {
+		validateImdbId(v, movie.ImdbId)
+	}
+
+	if movie.TmdbId != 0 {
+		validateTmdbId(v, movie.TmdbId)
+	}
 }
 
-func ValidateUpdateMovie(v *validator.Validator, update *UpdateMovie) {
+func ValidateUpdateMovie(v *validator.Validator, update *UpdateMovie, catalogue *domain.GenreCatalogue) {
 	if update.Title != nil {
 		v.Check(*update.Title != "", "title", "must be provided")
 		v.Check(len(*update.Title) <= 500, "title", "must not be more than 500 bytes long")
@@ -114,13 +225,171 @@ func ValidateUpdateMovie(v *validator.Validator, update *UpdateMovie) {
 	}
 
 	if update.Genres != nil {
-		v.Check(len(update.Genres) >= 1, "genres", "must contain at least 1 genre")
-		v.Check(len(update.Genres) <= 5, "genres", "must not contain more than 5 genres")
-		v.Check(validator.Unique(update.Genres), "genres", "must not contain duplicate values")
+		validateGenres(v, update.Genres, catalogue)
+	}
+
+	if update.ImdbId != nil {
+		validateImdbId(v, *update.ImdbId)
 	}
+
+	if update.TmdbId != nil {
+		validateTmdbId(v, *update.TmdbId)
+	}
+}
+
+func validateImdbId(v *validator.Validator, imdbId string) {
+	v.Check(validator.Matches(imdbId, ImdbIdRX), "imdb_id", "must be an IMDb title id such as tt0133093")
+}
+
+func validateTmdbId(v *validator.Validator, tmdbId int64) {
+	v.Check(tmdbId > 0, "tmdb_id", "must be a positive integer")
+}
+
+// NewMovieDocument returns the fields of movie a client may edit, in the
+// form utils/jsonpatch applies patches to. External ids that aren't set are
+// left out, so a patch can add them and removing them clears them.
+func NewMovieDocument(movie *domain.Movie) map[string]any {
+	genres := make([]any, len(movie.Genres))
+	for i, genre := range movie.Genres {
+		genres[i] = genre
+	}
+
+	doc := map[string]any{
+		"title":   movie.Title,
+		"year":    json.Number(strconv.Itoa(int(movie.Year))),
+		"runtime": json.Number(strconv.Itoa(int(movie.Runtime))),
+		"genres":  genres,
+	}
+
+	if movie.ImdbId != "" {
+		doc["imdb_id"] = movie.ImdbId
+	}
+
+	if movie.TmdbId != 0 {
+		doc["tmdb_id"] = json.Number(strconv.FormatInt(movie.TmdbId, 10))
+	}
+
+	return doc
+}
+
+// ReadMovieDocument reads a patched movie document back into a Movie. A
+// document that isn't an object, has fields a movie doesn't or values of
+// the wrong type is reported through v and nil is returned.
+func ReadMovieDocument(v *validator.Validator, doc any) *Movie {
+	if _, ok := doc.(map[string]any); !ok {
+		v.AddError("movie", "must be a JSON object")
+		return nil
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		v.AddError("movie", "must be a valid JSON document")
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.DisallowUnknownFields()
+
+	var movie Movie
+	if err = dec.Decode(&movie); err != nil {
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &unmarshalTypeError):
+			v.AddError(strings.SplitN(unmarshalTypeError.Field, ".", 2)[0], "has the wrong type")
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			v.AddError(strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`), "is not a movie field")
+		default:
+			v.AddError("movie", "must be a valid JSON document")
+		}
+		return nil
+	}
+
+	return &movie
+}
+
+// Update returns an UpdateMovie that sets every field to the value in m,
+// clearing the external ids m leaves empty.
+func (m *Movie) Update() *UpdateMovie {
+	return &UpdateMovie{
+		Title:   &m.Title,
+		Year:    &m.Year,
+		Runtime: &m.Runtime,
+		Genres:  m.Genres,
+		ImdbId:  &m.ImdbId,
+		TmdbId:  &m.TmdbId,
+	}
+}
+
+func ValidateQueryMovie(v *validator.Validator, q *QueryMovie) {
+	validateMovieFilters(v, q)
+
+	if q.Filters.Sort == "relevance" {
+		v.Check(!q.Filters.UseCursor, "sort", "relevance cannot be combined with cursor pagination")
+	}
+
+	for _, facet := range q.Facets {
+		v.Check(validator.PermittedValue(facet, "genres", "year", "decade"), "facets", "must only contain genres, year or decade")
+	}
+	v.Check(validator.Unique(q.Facets), "facets", "must not contain duplicate values")
+
+	ValidateFilters(v, q.Filters)
+}
+
+func ValidateExportMovie(v *validator.Validator, q *QueryMovie, format string) {
+	validateMovieFilters(v, q)
+	v.Check(validator.PermittedValue(format, "ndjson", "csv", "json"), "format", "must be ndjson, csv or json")
+	v.Check(validator.PermittedValue(q.Filters.Sort, q.Filters.SortSafeList...), "sort", "invalid sort value")
+}
+
+// validateMovieFilters checks the filters shared by every query that lists
+// movies.
+func validateMovieFilters(v *validator.Validator, q *QueryMovie) {
+	if q.YearFrom != 0 {
+		v.Check(q.YearFrom >= 1888, "year_from", "must be greater than 1888")
+		v.Check(q.YearFrom <= time.Now().Year(), "year_from", "must not be in the future")
+	}
+
+	if q.YearTo != 0 {
+		v.Check(q.YearTo >= 1888, "year_to", "must be greater than 1888")
+		v.Check(q.YearFrom <= q.YearTo, "year_to", "must not be before year_from")
+	}
+
+	if q.RuntimeMin != 0 {
+		v.Check(q.RuntimeMin > 0, "runtime_min", "must be a positive integer")
+	}
+
+	if q.RuntimeMax != 0 {
+		v.Check(q.RuntimeMax > 0, "runtime_max", "must be a positive integer")
+		v.Check(q.RuntimeMin <= q.RuntimeMax, "runtime_max", "must not be less than runtime_min")
+	}
+
+	if q.PersonId != 0 {
+		v.Check(q.PersonId > 0, "person_id", "must be a positive integer")
+	}
+
+	if q.Filters.Sort == "relevance" {
+		v.Check(q.Title != "", "sort", "relevance requires a title to search for")
+	}
+
+	v.Check(len(q.Genres) <= 20, "genres", "must not contain more than 20 genres")
+	v.Check(len(q.ExcludeGenres) <= 20, "exclude_genres", "must not contain more than 20 genres")
+	for _, genre := range q.ExcludeGenres {
+		v.Check(!slices.Contains(q.Genres, genre), "exclude_genres", "must not contain a genre that is also in genres")
+	}
+}
+
+func ValidateSuggestMovie(v *validator.Validator, s *SuggestMovie) {
+	v.Check(s.Query != "", "q", "must be provided")
+	v.Check(len(s.Query) <= 100, "q", "must not be more than 100 bytes long")
+	v.Check(s.Limit > 0, "limit", "must be greater than zero")
+	v.Check(s.Limit <= 20, "limit", "must be a maximum of 20")
 }
 
 func ValidateFilters(v *validator.Validator, f Filters) {
+	if f.UseCursor {
+		v.Check(f.Page == 1, "page", "must not be combined with cursor pagination")
+		v.Check(f.Cursor == nil || f.Cursor.Sort == f.Sort, "cursor", "does not match the sort parameter")
+	}
 	v.Check(f.Page > 0, "page", "must be greater than zero")
 	v.Check(f.Page <= 10_000_000, "page", "must be a maximum of 10 million")
 	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
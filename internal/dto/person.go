@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"time"
+)
+
+type Person struct {
+	Name      string `json:"name"`
+	BirthYear int32  `json:"birth_year"`
+	Biography string `json:"biography"`
+}
+
+type UpdatePerson struct {
+	Name      *string `json:"name"`
+	BirthYear *int32  `json:"birth_year"`
+	Biography *string `json:"biography"`
+}
+
+type Credit struct {
+	PersonId     int64  `json:"person_id"`
+	Role         string `json:"role"`
+	Character    string `json:"character"`
+	BillingOrder int32  `json:"billing_order"`
+}
+
+func validatePersonName(v *validator.Validator, name string) {
+	v.Check(name != "", "name", "must be provided")
+	v.Check(len(name) <= 500, "name", "must not be more than 500 bytes long")
+}
+
+func validateBirthYear(v *validator.Validator, year int32) {
+	v.Check(year >= 1800, "birth_year", "must be greater than 1800")
+	v.Check(year <= int32(time.Now().Year()), "birth_year", "must not be in the future")
+}
+
+func validateBiography(v *validator.Validator, biography string) {
+	v.Check(len(biography) <= 10_000, "biography", "must not be more than 10000 bytes long")
+}
+
+func ValidatePerson(v *validator.Validator, person *Person) {
+	validatePersonName(v, person.Name)
+	if person.BirthYear != 0 {
+		validateBirthYear(v, person.BirthYear)
+	}
+	validateBiography(v, person.Biography)
+}
+
+func ValidateUpdatePerson(v *validator.Validator, update *UpdatePerson) {
+	if update.Name != nil {
+		validatePersonName(v, *update.Name)
+	}
+
+	if update.BirthYear != nil && *update.BirthYear != 0 {
+		validateBirthYear(v, *update.BirthYear)
+	}
+
+	if update.Biography != nil {
+		validateBiography(v, *update.Biography)
+	}
+}
+
+func ValidateCredit(v *validator.Validator, credit *Credit) {
+	v.Check(credit.PersonId > 0, "person_id", "must be provided")
+	v.Check(validator.PermittedValue(credit.Role, domain.RoleDirector, domain.RoleWriter, domain.RoleActor), "role", "must be one of director, writer or actor")
+	v.Check(len(credit.Character) <= 500, "character", "must not be more than 500 bytes long")
+	v.Check(credit.Character == "" || credit.Role == domain.RoleActor, "character", "must only be given for actors")
+	v.Check(credit.BillingOrder >= 0, "billing_order", "must not be negative")
+}
+
+type QueryPerson struct {
+	Name    string
+	Filters Filters
+}
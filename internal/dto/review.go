@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+
+type Review struct {
+	Rating int32  `json:"rating"`
+	Body   string `json:"body"`
+}
+
+type QueryReview struct {
+	MovieId int64
+	Filters Filters
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Rating >= 1, "rating", "must be at least 1")
+	v.Check(review.Rating <= 10, "rating", "must not be more than 10")
+	v.Check(len(review.Body) <= 10_000, "body", "must not be more than 10000 bytes long")
+}
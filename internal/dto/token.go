@@ -12,3 +12,47 @@ func ValidateTokenPlaintext(v *validator.Validator, user *ActivateUser) {
 	v.Check(user.TokenPlaintext != "", "token", "must be provided")
 	v.Check(len(user.TokenPlaintext) == 26, "token", "must contain 26 characters")
 }
+
+type CreateAuthenticationToken struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func ValidateAuthenticationToken(v *validator.Validator, input *CreateAuthenticationToken) {
+	ValidateEmail(v, input.Email)
+	ValidatePassword(v, input.Password)
+}
+
+type CreatePasswordResetToken struct {
+	Email string `json:"email"`
+}
+
+func ValidatePasswordResetToken(v *validator.Validator, input *CreatePasswordResetToken) {
+	ValidateEmail(v, input.Email)
+}
+
+type ResetPassword struct {
+	Password       string `json:"password"`
+	TokenPlaintext string `json:"token"`
+}
+
+func ValidateResetPassword(v *validator.Validator, input *ResetPassword) {
+	ValidatePassword(v, input.Password)
+	ValidateTokenPlaintext(v, &ActivateUser{TokenPlaintext: input.TokenPlaintext})
+}
+
+type ConfirmEmailChange struct {
+	TokenPlaintext string `json:"token"`
+}
+
+func ValidateConfirmEmailChange(v *validator.Validator, input *ConfirmEmailChange) {
+	ValidateTokenPlaintext(v, &ActivateUser{TokenPlaintext: input.TokenPlaintext})
+}
+
+type CreateActivationToken struct {
+	Email string `json:"email"`
+}
+
+func ValidateActivationToken(v *validator.Validator, input *CreateActivationToken) {
+	ValidateEmail(v, input.Email)
+}
@@ -14,6 +14,13 @@ type User struct {
 	Version   int       `json:"version"`
 }
 
+type UpdateUser struct {
+	Name            *string `json:"name"`
+	Email           *string `json:"email"`
+	Password        *string `json:"password"`
+	CurrentPassword *string `json:"current_password"`
+}
+
 func ValidateEmail(v *validator.Validator, email string) {
 	v.Check(email != "", "email", "must be provided")
 	v.Check(validator.Matches(email, validator.EmailRX), "email", "must be a valid email address")
@@ -33,3 +40,19 @@ func ValidateUser(v *validator.Validator, user *User) {
 		ValidatePassword(v, user.Password)
 	}
 }
+
+func ValidateUpdateUser(v *validator.Validator, update *UpdateUser) {
+	if update.Name != nil {
+		v.Check(*update.Name != "", "name", "must be provided")
+		v.Check(len(*update.Name) <= 500, "name", "must not be more than 500 bytes long")
+	}
+
+	if update.Email != nil {
+		ValidateEmail(v, *update.Email)
+	}
+
+	if update.Password != nil {
+		ValidatePassword(v, *update.Password)
+		v.Check(update.CurrentPassword != nil && *update.CurrentPassword != "", "current_password", "must be provided to change the password")
+	}
+}
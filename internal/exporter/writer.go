@@ -0,0 +1,153 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ContentTypes maps every format to the media type it is served as.
+var ContentTypes = map[string]string{
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatJSON:   "application/json",
+}
+
+// csvHeader names the CSV columns. Genres share a single field, separated
+// by "|" as the bulk import expects them, and the import skips the columns
+// it can't set (id, rating, rating_count and version), so an export can be
+// fed back into it.
+var csvHeader = []string{"id", "title", "year", "runtime", "genres", "rating", "rating_count", "imdb_id", "tmdb_id", "version"}
+
+// Writer encodes movies one at a time. Output is buffered until Flush or
+// Close; Close also finishes the document (the closing bracket of the JSON
+// format) and must be called once every movie has been written.
+type Writer interface {
+	Write(movie *domain.Movie) error
+	Flush() error
+	Close() error
+}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	buf := bufio.NewWriter(w)
+
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}, nil
+	case FormatCSV:
+		cw := csv.NewWriter(buf)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{buf: buf, csv: cw}, nil
+	case FormatJSON:
+		if _, err := buf.WriteString(`{"movies":[`); err != nil {
+			return nil, err
+		}
+		return &jsonWriter{buf: buf}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(movie *domain.Movie) error {
+	return n.enc.Encode(movie)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.buf.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.buf.Flush()
+}
+
+type csvWriter struct {
+	buf *bufio.Writer
+	csv *csv.Writer
+}
+
+func (c *csvWriter) Write(movie *domain.Movie) error {
+	tmdbId := ""
+	if movie.TmdbId != 0 {
+		tmdbId = strconv.FormatInt(movie.TmdbId, 10)
+	}
+
+	return c.csv.Write([]string{
+		strconv.FormatInt(movie.Id, 10),
+		movie.Title,
+		strconv.Itoa(int(movie.Year)),
+		strconv.Itoa(int(movie.Runtime)),
+		strings.Join(movie.Genres, "|"),
+		strconv.FormatFloat(movie.Rating, 'f', -1, 64),
+		strconv.Itoa(int(movie.RatingCount)),
+		movie.ImdbId,
+		tmdbId,
+		strconv.Itoa(int(movie.Version)),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+// jsonWriter writes the same {"movies": [...]} envelope GET /v1/movies
+// answers with, one element at a time.
+type jsonWriter struct {
+	buf     *bufio.Writer
+	written bool
+}
+
+func (j *jsonWriter) Write(movie *domain.Movie) error {
+	js, err := json.Marshal(movie)
+	if err != nil {
+		return err
+	}
+
+	if j.written {
+		if err = j.buf.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	j.written = true
+
+	_, err = j.buf.Write(js)
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	return j.buf.Flush()
+}
+
+func (j *jsonWriter) Close() error {
+	if _, err := j.buf.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return j.buf.Flush()
+}
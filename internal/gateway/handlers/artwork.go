@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type ArtworkHandler struct {
+	logger         *slog.Logger
+	customError    *helper.CustomError
+	artworkService service.ArtworkService
+	maxUploadSize  int64
+}
+
+func (a *ArtworkHandler) UploadPoster(w http.ResponseWriter, r *http.Request) {
+	a.upload(w, r, domain.ArtworkPoster)
+}
+
+func (a *ArtworkHandler) UploadBackdrop(w http.ResponseWriter, r *http.Request) {
+	a.upload(w, r, domain.ArtworkBackdrop)
+}
+
+// upload takes the image from the "image" field of a multipart form.
+func (a *ArtworkHandler) upload(w http.ResponseWriter, r *http.Request, kind string) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		a.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.maxUploadSize)
+
+	if err = r.ParseMultipartForm(a.maxUploadSize); err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			a.customError.BadRequestResponse(w, r, fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit))
+		default:
+			a.customError.BadRequestResponse(w, r, errors.New("body must be a multipart form"))
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	v := validator.NewValidator()
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		v.AddError("image", "must be provided")
+		a.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+	defer file.Close()
+
+	movie, err := a.artworkService.SetArtwork(r.Context(), id, kind, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			a.customError.NotFoundResponse(w, r)
+		case errors.Is(err, utils.ErrInvalidImage):
+			v.AddError("image", strings.TrimPrefix(err.Error(), utils.ErrInvalidImage.Error()+": "))
+			a.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			a.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movie}, nil); err != nil {
+		a.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewArtworkHandler(logger *slog.Logger, customError *helper.CustomError, artworkService service.ArtworkService, maxUploadSize int64) *ArtworkHandler {
+	return &ArtworkHandler{
+		logger:         logger,
+		customError:    customError,
+		artworkService: artworkService,
+		maxUploadSize:  maxUploadSize,
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"log/slog"
+	"net/http"
+)
+
+type CreditHandler struct {
+	logger        *slog.Logger
+	customError   *helper.CustomError
+	creditService service.CreditService
+}
+
+func (c *CreditHandler) CreateCredit(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		c.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.Credit
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		c.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateCredit(v, payload)
+	if !v.Valid() {
+		c.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	credit, err := c.creditService.CreateCredit(r.Context(), movieId, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			c.customError.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrPersonNotFound):
+			v.AddError("person_id", "does not refer to an existing person")
+			c.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateCredit):
+			v.AddError("person_id", "already has this credit on the movie")
+			c.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			c.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"credit": credit}, nil); err != nil {
+		c.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CreditHandler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		c.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	credits, err := c.creditService.GetCreditsForMovie(r.Context(), movieId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			c.customError.NotFoundResponse(w, r)
+		default:
+			c.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"credits": credits}, nil); err != nil {
+		c.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CreditHandler) GetFilmography(w http.ResponseWriter, r *http.Request) {
+	personId, err := helper.ReadIdParam(r)
+	if err != nil {
+		c.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	credits, err := c.creditService.GetFilmography(r.Context(), personId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			c.customError.NotFoundResponse(w, r)
+		default:
+			c.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"filmography": credits}, nil); err != nil {
+		c.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (c *CreditHandler) DeleteCredit(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		c.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	creditId, err := helper.ReadIdParamByName(r, "credit_id")
+	if err != nil {
+		c.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = c.creditService.DeleteCredit(r.Context(), movieId, creditId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			c.customError.NotFoundResponse(w, r)
+		default:
+			c.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "credit successfully deleted"}, nil); err != nil {
+		c.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewCreditHandler(logger *slog.Logger, customError *helper.CustomError, creditService service.CreditService) *CreditHandler {
+	return &CreditHandler{
+		logger:        logger,
+		customError:   customError,
+		creditService: creditService,
+	}
+}
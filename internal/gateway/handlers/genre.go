@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"log/slog"
+	"net/http"
+)
+
+type GenreHandler struct {
+	logger       *slog.Logger
+	customError  *helper.CustomError
+	genreService service.GenreService
+}
+
+func (g *GenreHandler) CreateGenre(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.Genre
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		g.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateGenre(v, payload)
+	if !v.Valid() {
+		g.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	genre, err := g.genreService.CreateGenre(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateGenre):
+			v.AddError("slug", "the slug, name or one of the aliases is already used by another genre")
+			g.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			g.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/genres/%d", genre.Id))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"genre": genre}, headers); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (g *GenreHandler) GetGenreById(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		g.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	genre, err := g.genreService.GetGenreById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			g.customError.NotFoundResponse(w, r)
+		default:
+			g.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"genre": genre}, nil); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (g *GenreHandler) GetGenres(w http.ResponseWriter, r *http.Request) {
+	genres, err := g.genreService.GetGenres(r.Context())
+	if err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"genres": genres}, nil); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (g *GenreHandler) UpdateGenre(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		g.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.UpdateGenre
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		g.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateUpdateGenre(v, payload)
+	if !v.Valid() {
+		g.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	genre, err := g.genreService.UpdateGenre(r.Context(), id, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			g.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrDuplicateGenre):
+			v.AddError("slug", "the slug, name or one of the aliases is already used by another genre")
+			g.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			g.customError.EditConflictResponse(w, r)
+		default:
+			g.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"genre": genre}, nil); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (g *GenreHandler) DeleteGenre(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		g.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = g.genreService.DeleteGenre(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			g.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrGenreInUse):
+			g.customError.GenreInUseResponse(w, r)
+		default:
+			g.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "genre successfully deleted"}, nil); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (g *GenreHandler) MergeGenre(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		g.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.MergeGenre
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		g.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateMergeGenre(v, id, payload)
+	if !v.Valid() {
+		g.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	genre, err := g.genreService.MergeGenre(r.Context(), id, payload.GenreId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			g.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrEditConflict):
+			g.customError.EditConflictResponse(w, r)
+		default:
+			g.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"genre": genre}, nil); err != nil {
+		g.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewGenreHandler(logger *slog.Logger, customError *helper.CustomError, genreService service.GenreService) *GenreHandler {
+	return &GenreHandler{
+		logger:       logger,
+		customError:  customError,
+		genreService: genreService,
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/importer"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"io"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+)
+
+type ImportHandler struct {
+	logger          *slog.Logger
+	customError     *helper.CustomError
+	importer        importer.Importer
+	maxBulkBodySize int64
+}
+
+// bulkFormats maps the content types BulkImport accepts to file formats.
+var bulkFormats = map[string]string{
+	"text/csv":             importer.FormatCSV,
+	"application/x-ndjson": importer.FormatNDJSON,
+}
+
+func (i *ImportHandler) ImportMovie(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.ImportMovie
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		i.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateImportMovie(v, payload, i.importer.Providers())
+	if !v.Valid() {
+		i.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	result, err := i.importer.Import(r.Context(), payload.Provider, payload.Id)
+	if err != nil {
+		var invalidMetadata *importer.InvalidMetadataError
+		switch {
+		case errors.Is(err, importer.ErrNotFound):
+			v.AddError("id", "was not found at the provider")
+			i.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.As(err, &invalidMetadata):
+			i.customError.FailedValidationResponse(w, r, invalidMetadata.Errors)
+		case errors.Is(err, repository.ErrDuplicateImdbId):
+			v.AddError("id", "has an IMDb id already used by another movie")
+			i.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateTmdbId):
+			v.AddError("id", "has a TMDB id already used by another movie")
+			i.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict), errors.Is(err, service.ErrPreconditionFailed):
+			i.customError.EditConflictResponse(w, r)
+		default:
+			i.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	headers := make(http.Header)
+	if result.Created {
+		status = http.StatusCreated
+		headers.Set("Location", fmt.Sprintf("/v1/movies/%d", result.Movie.Id))
+	}
+
+	env := helper.Envelope{"movie": result.Movie}
+	if len(result.SkippedGenres) > 0 {
+		env["skipped_genres"] = result.SkippedGenres
+	}
+
+	if err = helper.WriteJSON(w, status, env, headers); err != nil {
+		i.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+// BulkImport creates movies from a CSV or NDJSON body. The body is read in
+// full before anything is stored, so a request cut short never leaves half
+// of a file imported. Pass atomic=true to store nothing unless every row is
+// valid.
+func (i *ImportHandler) BulkImport(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, ok := bulkFormats[mediaType]
+	if !ok {
+		i.customError.UnsupportedMediaTypeResponse(w, r, slices.Sorted(maps.Keys(bulkFormats))...)
+		return
+	}
+
+	v := validator.NewValidator()
+	atomic := helper.ReadBool(r.URL.Query(), "atomic", false, v)
+	if !v.Valid() {
+		i.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, i.maxBulkBodySize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			i.customError.BadRequestResponse(w, r, fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit))
+		default:
+			i.customError.BadRequestResponse(w, r, err)
+		}
+		return
+	}
+
+	result, err := i.importer.ImportFile(r.Context(), bytes.NewReader(body), format, atomic)
+	if err != nil {
+		switch {
+		case errors.Is(err, importer.ErrIncomplete):
+			i.incompleteImportResponse(w, r, result, err)
+		case errors.Is(err, importer.ErrInvalidFile):
+			i.customError.BadRequestResponse(w, r, err)
+		default:
+			i.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if atomic && len(result.Errors) > 0 {
+		i.customError.ErrorResponse(w, r, http.StatusUnprocessableEntity, result.Errors)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"created": result.Created, "errors": result.Errors}, nil); err != nil {
+		i.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+// incompleteImportResponse answers a bulk import that stored part of the
+// file before failing. Alongside the error, it lists what was created and
+// which rows weren't, so the client can send only those again.
+func (i *ImportHandler) incompleteImportResponse(w http.ResponseWriter, r *http.Request, result *importer.FileResult, err error) {
+	status, message := http.StatusInternalServerError, "the server encountered a problem and could not import the whole file"
+	if errors.Is(err, importer.ErrInvalidFile) {
+		status, message = http.StatusBadRequest, err.Error()
+	} else {
+		i.customError.LogError(r, err)
+	}
+
+	env := helper.Envelope{"error": message, "created": result.Created, "errors": result.Errors}
+	if err = helper.WriteJSON(w, status, env, nil); err != nil {
+		i.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewImportHandler(logger *slog.Logger, customError *helper.CustomError, importer importer.Importer, maxBulkBodySize int64) *ImportHandler {
+	return &ImportHandler{
+		logger:          logger,
+		customError:     customError,
+		importer:        importer,
+		maxBulkBodySize: maxBulkBodySize,
+	}
+}
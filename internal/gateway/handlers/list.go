@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"log/slog"
+	"net/http"
+)
+
+type ListHandler struct {
+	logger      *slog.Logger
+	customError *helper.CustomError
+	listService service.ListService
+	cursorCodec *helper.CursorCodec
+}
+
+func (l *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.List
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		l.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateList(v, payload)
+	if !v.Valid() {
+		l.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	list, err := l.listService.CreateList(r.Context(), helper.ContextGetUser(r), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateList):
+			v.AddError("name", "is already used by another of your lists")
+			l.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/users/me/lists/%d", list.Id))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"list": list}, headers); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) GetLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := l.listService.GetLists(r.Context(), helper.ContextGetUser(r))
+	if err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"lists": lists}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	l.getList(w, r, l.listService.GetList)
+}
+
+// GetPublicList serves a list to anyone when it is public, and to its owner
+// either way.
+func (l *ListHandler) GetPublicList(w http.ResponseWriter, r *http.Request) {
+	l.getList(w, r, l.listService.GetVisibleList)
+}
+
+type listLookup func(ctx context.Context, user *domain.User, id int64) (*domain.List, error)
+
+func (l *ListHandler) getList(w http.ResponseWriter, r *http.Request, lookup listLookup) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	list, err := lookup(r.Context(), helper.ContextGetUser(r), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"list": list}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.UpdateList
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		l.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateUpdateList(v, payload)
+	if !v.Valid() {
+		l.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	list, err := l.listService.UpdateList(r.Context(), helper.ContextGetUser(r), id, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrDuplicateList):
+			v.AddError("name", "is already used by another of your lists")
+			l.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, service.ErrBuiltInList):
+			v.AddError("name", "cannot be changed on the watchlist or watched list")
+			l.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			l.customError.EditConflictResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"list": list}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = l.listService.DeleteList(r.Context(), helper.ContextGetUser(r), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrBuiltInList):
+			l.customError.BuiltInListResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "list successfully deleted"}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) GetListItems(w http.ResponseWriter, r *http.Request) {
+	l.getListItems(w, r, l.listService.GetList)
+}
+
+func (l *ListHandler) GetPublicListItems(w http.ResponseWriter, r *http.Request) {
+	l.getListItems(w, r, l.listService.GetVisibleList)
+}
+
+func (l *ListHandler) getListItems(w http.ResponseWriter, r *http.Request, lookup listLookup) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	payload := &dto.QueryListItems{ListId: id}
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	payload.Filters.Page = helper.ReadInt(qs, "page", 1, v)
+	payload.Filters.PageSize = helper.ReadInt(qs, "page_size", 20, v)
+	payload.Filters.Sort = "position"
+	payload.Filters.SortSafeList = []string{"position"}
+	payload.Filters.IncludeTotal = helper.ReadBool(qs, "include_total", false, v)
+
+	pagination := helper.ReadString(qs, "pagination", "page")
+	v.Check(validator.PermittedValue(pagination, "page", "cursor"), "pagination", "must be either page or cursor")
+	payload.Filters.UseCursor = pagination == "cursor"
+
+	if cursor := helper.ReadString(qs, "cursor", ""); cursor != "" {
+		payload.Filters.UseCursor = true
+		decoded, err := l.cursorCodec.Decode(cursor)
+		if err != nil {
+			v.AddError("cursor", "must be a cursor returned by a previous request")
+		}
+		payload.Filters.Cursor = decoded
+	}
+
+	dto.ValidateFilters(v, payload.Filters)
+	if !v.Valid() {
+		l.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	list, err := lookup(r.Context(), helper.ContextGetUser(r), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	items, metadata, err := l.listService.GetListItems(r.Context(), payload)
+	if err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if payload.Filters.UseCursor {
+		if err = l.setCursors(&metadata, items); err != nil {
+			l.customError.ServerErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"list": list, "movies": items, "metadata": metadata}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) AddListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.ListItem
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		l.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateListItem(v, payload)
+	if !v.Valid() {
+		l.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	item, err := l.listService.AddListItem(r.Context(), helper.ContextGetUser(r), id, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrMovieNotFound):
+			v.AddError("movie_id", "does not refer to an existing movie")
+			l.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateListItem):
+			v.AddError("movie_id", "is already on this list")
+			l.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"item": item}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) UpdateListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	movieId, err := helper.ReadIdParamByName(r, "movie_id")
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.UpdateListItem
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		l.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateUpdateListItem(v, payload)
+	if !v.Valid() {
+		l.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	item, err := l.listService.UpdateListItem(r.Context(), helper.ContextGetUser(r), id, movieId, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"item": item}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) RemoveListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	movieId, err := helper.ReadIdParamByName(r, "movie_id")
+	if err != nil {
+		l.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = l.listService.RemoveListItem(r.Context(), helper.ContextGetUser(r), id, movieId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			l.customError.NotFoundResponse(w, r)
+		default:
+			l.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "movie successfully removed from the list"}, nil); err != nil {
+		l.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) setCursors(metadata *dto.Metadata, items []*domain.ListItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var err error
+	if metadata.HasNext {
+		if metadata.NextCursor, err = l.cursorCodec.Encode(dto.NewListItemCursor(items[len(items)-1], false)); err != nil {
+			return err
+		}
+	}
+
+	if metadata.HasPrev {
+		if metadata.PrevCursor, err = l.cursorCodec.Encode(dto.NewListItemCursor(items[0], true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewListHandler(logger *slog.Logger, customError *helper.CustomError, listService service.ListService, cursorCodec *helper.CursorCodec) *ListHandler {
+	return &ListHandler{
+		logger:      logger,
+		customError: customError,
+		listService: listService,
+		cursorCodec: cursorCodec,
+	}
+}
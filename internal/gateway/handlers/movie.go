@@ -1,21 +1,46 @@
 package handlers
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/exporter"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils/jsonpatch"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// exportFlushEvery is how many movies ExportMovies writes between flushes.
+const exportFlushEvery = 500
+
+const (
+	mediaTypeJSON       = "application/json"
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// patchMediaTypes are the bodies UpdateMovie accepts.
+var patchMediaTypes = []string{mediaTypeJSON, mediaTypeMergePatch, mediaTypeJSONPatch}
+
 type MovieHandler struct {
-	logger       *slog.Logger
-	customError  *helper.CustomError
-	movieService service.MovieService
+	logger        *slog.Logger
+	customError   *helper.CustomError
+	movieService  service.MovieService
+	genreService  service.GenreService
+	cursorCodec   *helper.CursorCodec
+	exportTimeout time.Duration
 }
 
 func (m *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
@@ -25,8 +50,14 @@ func (m *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	catalogue, err := m.genreService.Catalogue(r.Context())
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	v := validator.NewValidator()
-	dto.ValidateMovie(v, payload)
+	dto.ValidateMovie(v, payload, catalogue)
 	if !v.Valid() {
 		m.customError.FailedValidationResponse(w, r, v.Errors)
 		return
@@ -34,7 +65,16 @@ func (m *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 
 	movie, err := m.movieService.CreateMovie(r.Context(), payload)
 	if err != nil {
-		m.customError.ServerErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, repository.ErrDuplicateImdbId):
+			v.AddError("imdb_id", "is already used by another movie")
+			m.customError.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateTmdbId):
+			v.AddError("tmdb_id", "is already used by another movie")
+			m.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			m.customError.ServerErrorResponse(w, r, err)
+		}
 		return
 	}
 
@@ -64,41 +104,348 @@ func (m *MovieHandler) GetMovieById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movie}, nil); err != nil {
+	etag, err := helper.ETag(movie.Id, movie.Version, movie)
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if helper.NoneMatch(r, etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("ETag", etag)
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movie}, headers); err != nil {
 		m.customError.ServerErrorResponse(w, r, err)
 	}
 }
 
-func (m *MovieHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
+// readMovieQuery reads the filters and sort order shared by GetMovies and
+// ExportMovies.
+func readMovieQuery(qs url.Values, v *validator.Validator) *dto.QueryMovie {
 	payload := &dto.QueryMovie{}
+	payload.Title = helper.ReadString(qs, "title", "")
+	payload.Genres = helper.ReadCSV(qs, "genres", []string{})
+	payload.GenresAny = helper.ReadBool(qs, "genres_any", false, v)
+	payload.ExcludeGenres = helper.ReadCSV(qs, "exclude_genres", []string{})
+	payload.YearFrom = helper.ReadInt(qs, "year_from", 0, v)
+	payload.YearTo = helper.ReadInt(qs, "year_to", 0, v)
+	payload.RuntimeMin = helper.ReadInt(qs, "runtime_min", 0, v)
+	payload.RuntimeMax = helper.ReadInt(qs, "runtime_max", 0, v)
+	payload.PersonId = helper.ReadInt(qs, "person_id", 0, v)
+	payload.Filters.Sort = helper.ReadString(qs, "sort", "id")
+	payload.Filters.SortSafeList = dto.MovieSortSafeList
+	return payload
+}
+
+func (m *MovieHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
 	v := validator.NewValidator()
 
 	qs := r.URL.Query()
-	payload.Title = helper.ReadString(qs, "title", "")
-	payload.Genres = helper.ReadCSV(qs, "genres", []string{})
+	payload := readMovieQuery(qs, v)
+	payload.Facets = helper.ReadCSV(qs, "facets", []string{})
 	payload.Filters.Page = helper.ReadInt(qs, "page", 1, v)
 	payload.Filters.PageSize = helper.ReadInt(qs, "page_size", 20, v)
-	payload.Filters.Sort = helper.ReadString(qs, "sort", "id")
-	payload.Filters.SortSafeList = []string{"id", "title", "year", "runtime", "-id", "-title", "-year", "-runtime"}
+	payload.Filters.IncludeTotal = helper.ReadBool(qs, "include_total", false, v)
+
+	pagination := helper.ReadString(qs, "pagination", "page")
+	v.Check(validator.PermittedValue(pagination, "page", "cursor"), "pagination", "must be either page or cursor")
+	payload.Filters.UseCursor = pagination == "cursor"
+
+	if cursor := helper.ReadString(qs, "cursor", ""); cursor != "" {
+		payload.Filters.UseCursor = true
+		decoded, err := m.cursorCodec.Decode(cursor)
+		if err != nil {
+			v.AddError("cursor", "must be a cursor returned by a previous request")
+		}
+		payload.Filters.Cursor = decoded
+	}
 
-	dto.ValidateFilters(v, payload.Filters)
+	dto.ValidateQueryMovie(v, payload)
 	if !v.Valid() {
 		m.customError.FailedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	catalogue, err := m.genreService.Catalogue(r.Context())
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	// Unknown genres are left as they are: they simply match nothing.
+	dto.NormalizeGenres(payload.Genres, catalogue)
+	dto.NormalizeGenres(payload.ExcludeGenres, catalogue)
+
 	movies, metadata, err := m.movieService.GetMovies(r.Context(), payload)
 	if err != nil {
 		m.customError.ServerErrorResponse(w, r, err)
 		return
 	}
 
-	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movies": movies, "metadata": metadata}, nil); err != nil {
+	if payload.Filters.UseCursor {
+		if err = m.setCursors(&metadata, movies, payload.Filters.Sort); err != nil {
+			m.customError.ServerErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	env := helper.Envelope{"movies": movies, "metadata": metadata}
+
+	if len(payload.Facets) > 0 {
+		facets, err := m.movieService.GetMovieFacets(r.Context(), payload)
+		if err != nil {
+			m.customError.ServerErrorResponse(w, r, err)
+			return
+		}
+		env["facets"] = facets
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, env, nil); err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+// ExportMovies streams every movie matching the GetMovies filters as ndjson,
+// csv or json, gzipped when the client accepts it. Rows are written as they
+// come from the database and flushed every exportFlushEvery movies. Nothing
+// is sent before the first movie, so a failing query still gets a proper
+// error response; a failure after that aborts the connection, so a client
+// can never mistake a cut-off export for a complete one.
+func (m *MovieHandler) ExportMovies(w http.ResponseWriter, r *http.Request) {
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	payload := readMovieQuery(qs, v)
+	format := helper.ReadString(qs, "format", exporter.FormatNDJSON)
+
+	dto.ValidateExportMovie(v, payload, format)
+	if !v.Valid() {
+		m.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	catalogue, err := m.genreService.Catalogue(r.Context())
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	dto.NormalizeGenres(payload.Genres, catalogue)
+	dto.NormalizeGenres(payload.ExcludeGenres, catalogue)
+
+	rc := http.NewResponseController(w)
+	if err = rc.SetWriteDeadline(time.Now().Add(m.exportTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
 		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	var out exporter.Writer
+	var gz *gzip.Writer
+
+	start := func() error {
+		w.Header().Set("Content-Type", exporter.ContentTypes[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="movies.%s"`, format))
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		var body io.Writer = w
+		if acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz = gzip.NewWriter(w)
+			body = gz
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		var err error
+		out, err = exporter.NewWriter(body, format)
+		return err
+	}
+
+	flush := func() error {
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		if gz != nil {
+			if err := gz.Flush(); err != nil {
+				return err
+			}
+		}
+		return rc.Flush()
+	}
+
+	count := 0
+	err = m.movieService.ExportMovies(r.Context(), payload, func(movie *domain.Movie) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := out.Write(movie); err != nil {
+			return err
+		}
+
+		if count++; count%exportFlushEvery == 0 {
+			return flush()
+		}
+		return nil
+	})
+
+	if err == nil && out == nil {
+		err = start()
+	}
+
+	if err == nil {
+		if err = out.Close(); err == nil && gz != nil {
+			err = gz.Close()
+		}
+	}
+
+	if err != nil {
+		if out == nil {
+			m.customError.ServerErrorResponse(w, r, err)
+			return
+		}
+		m.customError.LogError(r, err)
+		panic(http.ErrAbortHandler)
 	}
 }
 
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+func (m *MovieHandler) SuggestMovies(w http.ResponseWriter, r *http.Request) {
+	payload := &dto.SuggestMovie{}
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	payload.Query = strings.TrimSpace(helper.ReadString(qs, "q", ""))
+	payload.Limit = helper.ReadInt(qs, "limit", 10, v)
+
+	dto.ValidateSuggestMovie(v, payload)
+	if !v.Valid() {
+		m.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	suggestions, err := m.movieService.SuggestMovies(r.Context(), payload)
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"suggestions": suggestions}, nil); err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (m *MovieHandler) GetSimilarMovies(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		m.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var filters dto.Filters
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	filters.Page = helper.ReadInt(qs, "page", 1, v)
+	filters.PageSize = helper.ReadInt(qs, "page_size", 20, v)
+	filters.Sort = "score"
+	filters.SortSafeList = []string{"score"}
+	filters.IncludeTotal = helper.ReadBool(qs, "include_total", false, v)
+
+	pagination := helper.ReadString(qs, "pagination", "page")
+	v.Check(validator.PermittedValue(pagination, "page", "cursor"), "pagination", "must be either page or cursor")
+	filters.UseCursor = pagination == "cursor"
+
+	if cursor := helper.ReadString(qs, "cursor", ""); cursor != "" {
+		filters.UseCursor = true
+		decoded, err := m.cursorCodec.Decode(cursor)
+		if err != nil {
+			v.AddError("cursor", "must be a cursor returned by a previous request")
+		}
+		filters.Cursor = decoded
+	}
+
+	dto.ValidateFilters(v, filters)
+	if !v.Valid() {
+		m.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	similar, metadata, err := m.movieService.GetSimilarMovies(r.Context(), id, filters)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			m.customError.NotFoundResponse(w, r)
+		default:
+			m.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if filters.UseCursor && len(similar) > 0 {
+		if metadata.HasNext {
+			if metadata.NextCursor, err = m.cursorCodec.Encode(dto.NewSimilarCursor(similar[len(similar)-1], false)); err != nil {
+				m.customError.ServerErrorResponse(w, r, err)
+				return
+			}
+		}
+
+		if metadata.HasPrev {
+			if metadata.PrevCursor, err = m.cursorCodec.Encode(dto.NewSimilarCursor(similar[0], true)); err != nil {
+				m.customError.ServerErrorResponse(w, r, err)
+				return
+			}
+		}
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"similar": similar, "metadata": metadata}, nil); err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+// UpdateMovie dispatches on the Content-Type: a plain JSON body sets the
+// fields it contains, while a JSON Merge Patch (RFC 7396) or a JSON Patch
+// (RFC 6902) is applied to the current movie, which is then validated as a
+// whole.
 func (m *MovieHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "", mediaTypeJSON:
+		m.updateMovie(w, r)
+	case mediaTypeMergePatch:
+		m.patchMovie(w, r, jsonpatch.MergePatch)
+	case mediaTypeJSONPatch:
+		m.patchMovie(w, r, jsonpatch.Patch)
+	default:
+		w.Header().Set("Accept-Patch", strings.Join(patchMediaTypes, ", "))
+		m.customError.UnsupportedMediaTypeResponse(w, r, patchMediaTypes...)
+	}
+}
+
+func (m *MovieHandler) updateMovie(w http.ResponseWriter, r *http.Request) {
 	id, err := helper.ReadIdParam(r)
 	if err != nil {
 		m.customError.NotFoundResponse(w, r)
@@ -111,25 +458,132 @@ func (m *MovieHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	catalogue, err := m.genreService.Catalogue(r.Context())
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	v := validator.NewValidator()
-	dto.ValidateUpdateMovie(v, payload)
+	dto.ValidateUpdateMovie(v, payload, catalogue)
 	if !v.Valid() {
 		m.customError.FailedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	updatedMovie, err := m.movieService.UpdateMovie(r.Context(), id, payload)
+	version, ok := helper.IfMatchVersion(r, id)
+	if !ok {
+		m.customError.PreconditionFailedResponse(w, r)
+		return
+	}
+
+	updatedMovie, err := m.movieService.UpdateMovie(r.Context(), id, payload, version)
+	if err != nil {
+		m.updateMovieError(w, r, v, err)
+		return
+	}
+
+	m.writeUpdatedMovie(w, r, updatedMovie)
+}
+
+// errInvalidPatchedMovie stops a patch whose result fails validation; the
+// validation errors themselves are kept by the caller.
+var errInvalidPatchedMovie = errors.New("invalid patched movie")
+
+// patchMovie applies the patch to the movie as it is on the primary, with
+// the movie locked until the result is saved, so nothing can slip in between.
+func (m *MovieHandler) patchMovie(w http.ResponseWriter, r *http.Request, apply func(doc any, patch []byte) (any, error)) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		m.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	version, ok := helper.IfMatchVersion(r, id)
+	if !ok {
+		m.customError.PreconditionFailedResponse(w, r)
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1_048_576))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			err = fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit)
+		}
+		m.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	catalogue, err := m.genreService.Catalogue(r.Context())
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	updatedMovie, err := m.movieService.PatchMovie(r.Context(), id, version, func(movie *domain.Movie) (*dto.UpdateMovie, error) {
+		doc, err := apply(dto.NewMovieDocument(movie), patch)
+		if err != nil {
+			return nil, err
+		}
+
+		payload := dto.ReadMovieDocument(v, doc)
+		if v.Valid() {
+			dto.ValidateMovie(v, payload, catalogue)
+		}
+		if !v.Valid() {
+			return nil, errInvalidPatchedMovie
+		}
+		return payload.Update(), nil
+	})
 	if err != nil {
 		switch {
-		case errors.Is(err, repository.ErrEditConflict):
-			m.customError.EditConflictResponse(w, r)
+		case errors.Is(err, jsonpatch.ErrInvalidPatch):
+			m.customError.BadRequestResponse(w, r, err)
+		case errors.Is(err, jsonpatch.ErrConflict):
+			m.customError.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		case errors.Is(err, errInvalidPatchedMovie):
+			m.customError.FailedValidationResponse(w, r, v.Errors)
 		default:
-			m.customError.ServerErrorResponse(w, r, err)
+			m.updateMovieError(w, r, v, err)
 		}
 		return
 	}
 
-	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": updatedMovie}, nil); err != nil {
+	m.writeUpdatedMovie(w, r, updatedMovie)
+}
+
+func (m *MovieHandler) updateMovieError(w http.ResponseWriter, r *http.Request, v *validator.Validator, err error) {
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound):
+		m.customError.NotFoundResponse(w, r)
+	case errors.Is(err, service.ErrPreconditionFailed):
+		m.customError.PreconditionFailedResponse(w, r)
+	case errors.Is(err, repository.ErrDuplicateImdbId):
+		v.AddError("imdb_id", "is already used by another movie")
+		m.customError.FailedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, repository.ErrDuplicateTmdbId):
+		v.AddError("tmdb_id", "is already used by another movie")
+		m.customError.FailedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, repository.ErrEditConflict):
+		m.customError.EditConflictResponse(w, r)
+	default:
+		m.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (m *MovieHandler) writeUpdatedMovie(w http.ResponseWriter, r *http.Request, movie *domain.Movie) {
+	etag, err := helper.ETag(movie.Id, movie.Version, movie)
+	if err != nil {
+		m.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("ETag", etag)
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movie}, headers); err != nil {
 		m.customError.ServerErrorResponse(w, r, err)
 	}
 }
@@ -141,10 +595,18 @@ func (m *MovieHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = m.movieService.DeleteMovie(r.Context(), id); err != nil {
+	version, ok := helper.IfMatchVersion(r, id)
+	if !ok {
+		m.customError.PreconditionFailedResponse(w, r)
+		return
+	}
+
+	if err = m.movieService.DeleteMovie(r.Context(), id, version); err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			m.customError.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrPreconditionFailed):
+			m.customError.PreconditionFailedResponse(w, r)
 		default:
 			m.customError.ServerErrorResponse(w, r, err)
 		}
@@ -156,10 +618,36 @@ func (m *MovieHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func NewMovieHandler(logger *slog.Logger, customError *helper.CustomError, movieService service.MovieService) *MovieHandler {
+func (m *MovieHandler) setCursors(metadata *dto.Metadata, movies []*domain.Movie, sort string) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	var err error
+	if metadata.HasNext {
+		if metadata.NextCursor, err = m.cursorCodec.Encode(dto.NewCursor(movies[len(movies)-1], sort, false)); err != nil {
+			return err
+		}
+	}
+
+	if metadata.HasPrev {
+		if metadata.PrevCursor, err = m.cursorCodec.Encode(dto.NewCursor(movies[0], sort, true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewMovieHandler takes the longest an export may spend writing its
+// response, which overrides the server's write timeout for exports.
+func NewMovieHandler(logger *slog.Logger, customError *helper.CustomError, movieService service.MovieService, genreService service.GenreService, cursorCodec *helper.CursorCodec, exportTimeout time.Duration) *MovieHandler {
 	return &MovieHandler{
-		logger:       logger,
-		customError:  customError,
-		movieService: movieService,
+		logger:        logger,
+		customError:   customError,
+		movieService:  movieService,
+		genreService:  genreService,
+		cursorCodec:   cursorCodec,
+		exportTimeout: exportTimeout,
 	}
 }
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storedMovie serves the one movie a patch is applied to.
+type storedMovie struct {
+	service.MovieService
+	movie domain.Movie
+}
+
+func (s *storedMovie) PatchMovie(_ context.Context, id int64, version int32, patch func(movie *domain.Movie) (*dto.UpdateMovie, error)) (*domain.Movie, error) {
+	if id != s.movie.Id {
+		return nil, repository.ErrRecordNotFound
+	}
+	if version != 0 && version != s.movie.Version {
+		return nil, service.ErrPreconditionFailed
+	}
+
+	current := s.movie
+	input, err := patch(&current)
+	if err != nil {
+		return nil, err
+	}
+
+	s.movie.Title, s.movie.Year, s.movie.Runtime, s.movie.Genres = *input.Title, *input.Year, *input.Runtime, input.Genres
+	s.movie.ImdbId, s.movie.TmdbId = *input.ImdbId, *input.TmdbId
+	s.movie.Version++
+
+	updated := s.movie
+	return &updated, nil
+}
+
+type catalogueOnly struct {
+	service.GenreService
+}
+
+func (catalogueOnly) Catalogue(context.Context) (*domain.GenreCatalogue, error) {
+	return domain.NewGenreCatalogue([]*domain.Genre{
+		{Slug: "action", Name: "Action"},
+		{Slug: "drama", Name: "Drama"},
+		{Slug: "science-fiction", Name: "Science Fiction", Aliases: []string{"Sci-Fi"}},
+	}), nil
+}
+
+// patch sends a PATCH for movie 1, The Matrix at version 3, and returns the
+// response together with the movie as it was left. An empty ifMatch sends
+// no If-Match header.
+func patch(t *testing.T, contentType, ifMatch, body string) (*httptest.ResponseRecorder, domain.Movie) {
+	t.Helper()
+
+	movies := &storedMovie{movie: domain.Movie{
+		Id:      1,
+		Title:   "The Matrix",
+		Year:    1999,
+		Runtime: 136,
+		Genres:  []string{"action"},
+		ImdbId:  "tt0133093",
+		Version: 3,
+	}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewMovieHandler(logger, helper.NewCustomErr(logger), movies, catalogueOnly{}, nil, time.Minute)
+
+	r := httptest.NewRequest(http.MethodPatch, "/v1/movies/1", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	if ifMatch != "" {
+		r.Header.Set("If-Match", ifMatch)
+	}
+	r = r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, httprouter.Params{{Key: "id", Value: "1"}}))
+
+	w := httptest.NewRecorder()
+	handler.UpdateMovie(w, r)
+
+	return w, movies.movie
+}
+
+func TestUpdateMovieJSONPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		patch      string
+		status     int
+		wantGenres []string
+		wantError  string
+	}{
+		{
+			name:       "append a genre",
+			patch:      `[{"op": "add", "path": "/genres/-", "value": "Sci-Fi"}]`,
+			status:     http.StatusOK,
+			wantGenres: []string{"action", "science-fiction"},
+		},
+		{
+			name:       "test then replace",
+			patch:      `[{"op": "test", "path": "/genres/0", "value": "action"}, {"op": "replace", "path": "/genres/0", "value": "drama"}]`,
+			status:     http.StatusOK,
+			wantGenres: []string{"drama"},
+		},
+		{
+			name:      "unknown genre",
+			patch:     `[{"op": "add", "path": "/genres/-", "value": "Cyberpunk"}]`,
+			status:    http.StatusUnprocessableEntity,
+			wantError: "genres",
+		},
+		{
+			name:      "duplicate genre",
+			patch:     `[{"op": "add", "path": "/genres/-", "value": "Action"}]`,
+			status:    http.StatusUnprocessableEntity,
+			wantError: "genres",
+		},
+		{
+			name:      "remove a required field",
+			patch:     `[{"op": "remove", "path": "/title"}]`,
+			status:    http.StatusUnprocessableEntity,
+			wantError: "title",
+		},
+		{
+			name:      "wrong type",
+			patch:     `[{"op": "replace", "path": "/year", "value": "1999"}]`,
+			status:    http.StatusUnprocessableEntity,
+			wantError: "year",
+		},
+		{
+			name:      "field a movie doesn't have",
+			patch:     `[{"op": "add", "path": "/rating", "value": 5}]`,
+			status:    http.StatusUnprocessableEntity,
+			wantError: "rating",
+		},
+		{
+			name:   "failed test",
+			patch:  `[{"op": "test", "path": "/title", "value": "Alien"}]`,
+			status: http.StatusConflict,
+		},
+		{
+			name:   "malformed patch",
+			patch:  `{"op": "remove", "path": "/title"}`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, movie := patch(t, "application/json-patch+json", "", tt.patch)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body)
+			}
+
+			if tt.status != http.StatusOK {
+				var body struct {
+					Error map[string]json.RawMessage `json:"error"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &body)
+
+				if movie.Version != 3 {
+					t.Errorf("movie saved at version %d, want it untouched", movie.Version)
+				}
+				if _, ok := body.Error[tt.wantError]; tt.wantError != "" && !ok {
+					t.Errorf("errors = %s, want one on %q", w.Body, tt.wantError)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(movie.Genres, tt.wantGenres) {
+				t.Errorf("genres saved = %v, want %v", movie.Genres, tt.wantGenres)
+			}
+			if movie.ImdbId != "tt0133093" {
+				t.Errorf("imdb_id = %q, want it kept", movie.ImdbId)
+			}
+			if w.Header().Get("ETag") == "" {
+				t.Error("no ETag on the response")
+			}
+		})
+	}
+}
+
+func TestUpdateMovieMergePatch(t *testing.T) {
+	w, movie := patch(t, "application/merge-patch+json", "", `{"title": "The Matrix Reloaded", "imdb_id": null}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if movie.Title != "The Matrix Reloaded" || movie.ImdbId != "" {
+		t.Errorf("movie = %+v, want the title changed and imdb_id cleared", movie)
+	}
+	if !reflect.DeepEqual(movie.Genres, []string{"action"}) {
+		t.Errorf("genres = %v, want them kept", movie.Genres)
+	}
+}
+
+func TestUpdateMoviePatchPreconditions(t *testing.T) {
+	tests := []struct {
+		ifMatch string
+		status  int
+	}{
+		{ifMatch: `"1-3-0000000000000000"`, status: http.StatusOK},
+		{ifMatch: `"1-2-0000000000000000"`, status: http.StatusPreconditionFailed},
+		{ifMatch: `"2-3-0000000000000000"`, status: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		w, _ := patch(t, "application/merge-patch+json", tt.ifMatch, `{"runtime": 150}`)
+
+		if w.Code != tt.status {
+			t.Errorf("If-Match %s: status = %d, want %d", tt.ifMatch, w.Code, tt.status)
+		}
+	}
+}
+
+func TestUpdateMovieUnsupportedMediaType(t *testing.T) {
+	w, _ := patch(t, "text/plain", "", `title=Alien`)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+	if w.Header().Get("Accept-Patch") == "" {
+		t.Error("no Accept-Patch header on the response")
+	}
+}
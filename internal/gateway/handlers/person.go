@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"log/slog"
+	"net/http"
+)
+
+type PersonHandler struct {
+	logger        *slog.Logger
+	customError   *helper.CustomError
+	personService service.PersonService
+}
+
+func (p *PersonHandler) CreatePerson(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.Person
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		p.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidatePerson(v, payload)
+	if !v.Valid() {
+		p.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	person, err := p.personService.CreatePerson(r.Context(), payload)
+	if err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/people/%d", person.Id))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"person": person}, headers); err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) GetPersonById(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		p.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	person, err := p.personService.GetPersonById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			p.customError.NotFoundResponse(w, r)
+		default:
+			p.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"person": person}, nil); err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) GetPeople(w http.ResponseWriter, r *http.Request) {
+	payload := &dto.QueryPerson{}
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	payload.Name = helper.ReadString(qs, "name", "")
+	payload.Filters.Page = helper.ReadInt(qs, "page", 1, v)
+	payload.Filters.PageSize = helper.ReadInt(qs, "page_size", 20, v)
+	payload.Filters.Sort = helper.ReadString(qs, "sort", "id")
+	payload.Filters.SortSafeList = []string{"id", "name", "birth_year", "-id", "-name", "-birth_year"}
+
+	dto.ValidateFilters(v, payload.Filters)
+	if !v.Valid() {
+		p.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	people, metadata, err := p.personService.GetPeople(r.Context(), payload)
+	if err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"people": people, "metadata": metadata}, nil); err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		p.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.UpdatePerson
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		p.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateUpdatePerson(v, payload)
+	if !v.Valid() {
+		p.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	person, err := p.personService.UpdatePerson(r.Context(), id, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			p.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrEditConflict):
+			p.customError.EditConflictResponse(w, r)
+		default:
+			p.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"person": person}, nil); err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) DeletePerson(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadIdParam(r)
+	if err != nil {
+		p.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = p.personService.DeletePerson(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			p.customError.NotFoundResponse(w, r)
+		default:
+			p.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "person successfully deleted"}, nil); err != nil {
+		p.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewPersonHandler(logger *slog.Logger, customError *helper.CustomError, personService service.PersonService) *PersonHandler {
+	return &PersonHandler{
+		logger:        logger,
+		customError:   customError,
+		personService: personService,
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"log/slog"
+	"net/http"
+)
+
+type ReviewHandler struct {
+	logger        *slog.Logger
+	customError   *helper.CustomError
+	reviewService service.ReviewService
+}
+
+func (rh *ReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		rh.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.Review
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		rh.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateReview(v, payload)
+	if !v.Valid() {
+		rh.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	review, err := rh.reviewService.CreateReview(r.Context(), helper.ContextGetUser(r), movieId, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			rh.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrDuplicateReview):
+			v.AddError("movie", "has already been reviewed by you; use PUT to change your review")
+			rh.customError.FailedValidationResponse(w, r, v.Errors)
+		default:
+			rh.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"review": review}, nil); err != nil {
+		rh.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		rh.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	payload := &dto.QueryReview{MovieId: movieId}
+	v := validator.NewValidator()
+
+	qs := r.URL.Query()
+	payload.Filters.Page = helper.ReadInt(qs, "page", 1, v)
+	payload.Filters.PageSize = helper.ReadInt(qs, "page_size", 20, v)
+	payload.Filters.Sort = helper.ReadString(qs, "sort", "-created_at")
+	payload.Filters.SortSafeList = []string{"created_at", "rating", "-created_at", "-rating"}
+
+	dto.ValidateFilters(v, payload.Filters)
+	if !v.Valid() {
+		rh.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	reviews, metadata, err := rh.reviewService.GetReviews(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			rh.customError.NotFoundResponse(w, r)
+		default:
+			rh.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"reviews": reviews, "metadata": metadata}, nil); err != nil {
+		rh.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		rh.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload *dto.Review
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		rh.customError.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateReview(v, payload)
+	if !v.Valid() {
+		rh.customError.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	review, err := rh.reviewService.UpdateReview(r.Context(), helper.ContextGetUser(r), movieId, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			rh.customError.NotFoundResponse(w, r)
+		case errors.Is(err, repository.ErrEditConflict):
+			rh.customError.EditConflictResponse(w, r)
+		default:
+			rh.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"review": review}, nil); err != nil {
+		rh.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	movieId, err := helper.ReadIdParam(r)
+	if err != nil {
+		rh.customError.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = rh.reviewService.DeleteReview(r.Context(), helper.ContextGetUser(r), movieId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			rh.customError.NotFoundResponse(w, r)
+		default:
+			rh.customError.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "review successfully deleted"}, nil); err != nil {
+		rh.customError.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewReviewHandler(logger *slog.Logger, customError *helper.CustomError, reviewService service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		logger:        logger,
+		customError:   customError,
+		reviewService: reviewService,
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils/email"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type TokenHandler struct {
+	customErr    *helper.CustomError
+	userService  service.UserService
+	tokenService service.TokenService
+	mailService  email.MailSender
+	logger       *slog.Logger
+
+	activationLimiter *helper.RateLimiter
+}
+
+func (t *TokenHandler) CreateAuthenticationToken(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.CreateAuthenticationToken
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		t.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateAuthenticationToken(v, payload)
+	if !v.Valid() {
+		t.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := t.userService.GetUserByEmail(r.Context(), payload.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			t.customErr.InvalidCredentialsResponse(w, r)
+		default:
+			t.customErr.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	match, err := user.Password.Matches(payload.Password)
+	if err != nil {
+		t.customErr.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if !match {
+		t.customErr.InvalidCredentialsResponse(w, r)
+		return
+	}
+
+	token, err := t.tokenService.Tokenize(r.Context(), user.Id, 24*time.Hour, domain.ScopeAuthentication)
+	if err != nil {
+		t.customErr.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"authentication_token": token}, nil); err != nil {
+		t.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+// CreatePasswordResetToken answers identically whether or not the email is
+// registered; the lookup and the email are done in the background so that the
+// response time doesn't give it away either.
+func (t *TokenHandler) CreatePasswordResetToken(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.CreatePasswordResetToken
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		t.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidatePasswordResetToken(v, payload)
+	if !v.Valid() {
+		t.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	helper.Background(func() {
+		ctx := context.Background()
+
+		user, err := t.userService.GetUserByEmail(ctx, payload.Email)
+		if err != nil {
+			if !errors.Is(err, repository.ErrRecordNotFound) {
+				t.logger.Error(err.Error())
+			}
+			return
+		}
+
+		if !user.Activated {
+			return
+		}
+
+		token, err := t.tokenService.Tokenize(ctx, user.Id, 45*time.Minute, domain.ScopePasswordReset)
+		if err != nil {
+			t.logger.Error(err.Error())
+			return
+		}
+
+		data := map[string]any{
+			"passwordResetToken": token.Plaintext,
+		}
+
+		if err := t.mailService.Send(user.Email, "password_reset.tmpl", data); err != nil {
+			t.logger.Error(err.Error())
+		}
+	})
+
+	env := helper.Envelope{"message": "if an account with that email address exists, you will receive password reset instructions shortly"}
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		t.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+// CreateActivationToken resends the activation email. Like
+// CreatePasswordResetToken it answers 202 whether the email is unknown,
+// already activated or waiting for activation, and does the lookup in the
+// background. It deliberately does not refuse an activated account: a
+// distinct answer for one would tell anyone which emails are registered.
+func (t *TokenHandler) CreateActivationToken(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.CreateActivationToken
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		t.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateActivationToken(v, payload)
+	if !v.Valid() {
+		t.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if !t.activationLimiter.Allow(strings.ToLower(payload.Email)) {
+		t.customErr.RateLimitExceededResponse(w, r)
+		return
+	}
+
+	helper.Background(func() {
+		ctx := context.Background()
+
+		user, err := t.userService.GetUserByEmail(ctx, payload.Email)
+		if err != nil {
+			if !errors.Is(err, repository.ErrRecordNotFound) {
+				t.logger.Error(err.Error())
+			}
+			return
+		}
+
+		if user.Activated {
+			return
+		}
+
+		if err = t.tokenService.DeleteAllForUser(ctx, domain.ScopeActivation, user.Id); err != nil {
+			t.logger.Error(err.Error())
+			return
+		}
+
+		token, err := t.tokenService.Tokenize(ctx, user.Id, 3*24*time.Hour, domain.ScopeActivation)
+		if err != nil {
+			t.logger.Error(err.Error())
+			return
+		}
+
+		data := map[string]any{
+			"activationToken": token.Plaintext,
+			"userId":          user.Id,
+		}
+
+		if err := t.mailService.Send(user.Email, "user_welcome.tmpl", data); err != nil {
+			t.logger.Error(err.Error())
+		}
+	})
+
+	env := helper.Envelope{"message": "if an account with that email address is awaiting activation, you will receive activation instructions shortly"}
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		t.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewTokenHandler(customErr *helper.CustomError, userService service.UserService, tokenService service.TokenService, mailService email.MailSender, activationLimiter *helper.RateLimiter, logger *slog.Logger) *TokenHandler {
+	return &TokenHandler{
+		customErr:         customErr,
+		userService:       userService,
+		tokenService:      tokenService,
+		mailService:       mailService,
+		activationLimiter: activationLimiter,
+		logger:            logger,
+	}
+}
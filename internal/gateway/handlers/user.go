@@ -37,7 +37,7 @@ func (u *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := u.userService.CreateUser(r.Context(), payload)
+	user, token, err := u.userService.CreateUser(r.Context(), payload)
 	if err != nil {
 		switch {
 		case errors.Is(err, repository.ErrDuplicateEmail):
@@ -49,12 +49,6 @@ func (u *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := u.tokenService.Tokenize(r.Context(), user.Id, 3*24*time.Hour, domain.ScopeActivation)
-	if err != nil {
-		u.customErr.ServerErrorResponse(w, r, err)
-		return
-	}
-
 	helper.Background(func() {
 		data := map[string]any{
 			"activationToken": token.Plaintext,
@@ -107,6 +101,158 @@ func (u *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (u *UserHandler) UpdateUserPassword(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.ResetPassword
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		u.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateResetPassword(v, payload)
+	if !v.Valid() {
+		u.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := u.tokenService.ResetPassword(r.Context(), payload); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired password reset token")
+			u.customErr.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			u.customErr.EditConflictResponse(w, r)
+		default:
+			u.customErr.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "your password was successfully reset"}, nil); err != nil {
+		u.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user := helper.ContextGetUser(r)
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		u.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.UpdateUser
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		u.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateUpdateUser(v, payload)
+	if !v.Valid() {
+		u.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := helper.ContextGetUser(r)
+	previousPendingEmail := user.PendingEmail
+
+	user, err := u.userService.UpdateUser(r.Context(), user, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPasswordMismatch):
+			v.AddError("current_password", "is incorrect")
+			u.customErr.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			v.AddError("email", "a user with this email address already exists")
+			u.customErr.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			u.customErr.EditConflictResponse(w, r)
+		default:
+			u.customErr.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if user.PendingEmail != nil && user.PendingEmail != previousPendingEmail {
+		if err = u.tokenService.DeleteAllForUser(r.Context(), domain.ScopeEmailChange, user.Id); err != nil {
+			u.customErr.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		token, err := u.tokenService.Tokenize(r.Context(), user.Id, 24*time.Hour, domain.ScopeEmailChange)
+		if err != nil {
+			u.customErr.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		currentEmail, pendingEmail := user.Email, *user.PendingEmail
+
+		helper.Background(func() {
+			data := map[string]any{
+				"emailChangeToken": token.Plaintext,
+				"pendingEmail":     pendingEmail,
+			}
+
+			if err := u.mailService.Send(pendingEmail, "email_change_confirm.tmpl", data); err != nil {
+				u.logger.Error(err.Error())
+			}
+
+			notice := map[string]any{
+				"pendingEmail": pendingEmail,
+			}
+
+			if err := u.mailService.Send(currentEmail, "email_change_notice.tmpl", notice); err != nil {
+				u.logger.Error(err.Error())
+			}
+		})
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		u.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.ConfirmEmailChange
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		u.customErr.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateConfirmEmailChange(v, payload)
+	if !v.Valid() {
+		u.customErr.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := u.tokenService.ConfirmEmailChange(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired email change token")
+			u.customErr.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			v.AddError("email", "a user with this email address already exists")
+			u.customErr.FailedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			u.customErr.EditConflictResponse(w, r)
+		default:
+			u.customErr.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		u.customErr.ServerErrorResponse(w, r, err)
+	}
+}
+
 func NewUserHandler(customErr *helper.CustomError, userService service.UserService, tokenService service.TokenService, mailService email.MailSender, logger *slog.Logger) *UserHandler {
 	return &UserHandler{
 		customErr:    customErr,
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type ArtworkRoutes struct {
+	artworkHandler *handlers.ArtworkHandler
+	middleware     *middleware.Middleware
+	files          http.Handler
+}
+
+func (a *ArtworkRoutes) ArtworkRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/poster", a.middleware.RequirePermission(domain.PermissionMoviesWrite, a.artworkHandler.UploadPoster))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/backdrop", a.middleware.RequirePermission(domain.PermissionMoviesWrite, a.artworkHandler.UploadBackdrop))
+	router.Handler(http.MethodGet, "/static/*filepath", http.StripPrefix("/static", a.files))
+}
+
+// NewArtworkRoutes takes the handler serving the stored files, which are
+// mounted under /static/.
+func NewArtworkRoutes(artworkHandler *handlers.ArtworkHandler, middleware *middleware.Middleware, files http.Handler) *ArtworkRoutes {
+	return &ArtworkRoutes{
+		artworkHandler: artworkHandler,
+		middleware:     middleware,
+		files:          files,
+	}
+}
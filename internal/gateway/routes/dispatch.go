@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// subResources lets fixed paths such as /v1/movies/suggest live next to an
+// :id wildcard, which httprouter refuses to register. Named segments go to
+// their own handlers and anything else falls through to byId.
+func subResources(byId http.HandlerFunc, named map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if handler, ok := named[httprouter.ParamsFromContext(r.Context()).ByName("id")]; ok {
+			handler(w, r)
+			return
+		}
+		byId(w, r)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type GenreRoutes struct {
+	genreHandler *handlers.GenreHandler
+	middleware   *middleware.Middleware
+}
+
+func (g *GenreRoutes) GenreRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, "/v1/genres", g.middleware.RequirePermission(domain.PermissionGenresWrite, g.genreHandler.CreateGenre))
+	router.HandlerFunc(http.MethodGet, "/v1/genres/:id", g.middleware.RequirePermission(domain.PermissionMoviesRead, g.genreHandler.GetGenreById))
+	router.HandlerFunc(http.MethodGet, "/v1/genres", g.middleware.RequirePermission(domain.PermissionMoviesRead, g.genreHandler.GetGenres))
+	router.HandlerFunc(http.MethodPatch, "/v1/genres/:id", g.middleware.RequirePermission(domain.PermissionGenresWrite, g.genreHandler.UpdateGenre))
+	router.HandlerFunc(http.MethodDelete, "/v1/genres/:id", g.middleware.RequirePermission(domain.PermissionGenresWrite, g.genreHandler.DeleteGenre))
+	router.HandlerFunc(http.MethodPost, "/v1/genres/:id/merge", g.middleware.RequirePermission(domain.PermissionGenresWrite, g.genreHandler.MergeGenre))
+}
+
+func NewGenreRoutes(genreHandler *handlers.GenreHandler, middleware *middleware.Middleware) *GenreRoutes {
+	return &GenreRoutes{
+		genreHandler: genreHandler,
+		middleware:   middleware,
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type ImportRoutes struct {
+	importHandler *handlers.ImportHandler
+	middleware    *middleware.Middleware
+	customError   *helper.CustomError
+}
+
+// ImportRoute registers POST /v1/movies/:id because the movie sub-resources
+// already hold that position; a POST to an actual movie id is not allowed.
+func (i *ImportRoutes) ImportRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id", subResources(
+		i.customError.MethodNotAllowedResponse,
+		map[string]http.HandlerFunc{
+			"import": i.middleware.RequirePermission(domain.PermissionMoviesImport, i.importHandler.ImportMovie),
+			"bulk":   i.middleware.RequirePermission(domain.PermissionMoviesImport, i.importHandler.BulkImport),
+		},
+	))
+}
+
+func NewImportRoutes(importHandler *handlers.ImportHandler, middleware *middleware.Middleware, customError *helper.CustomError) *ImportRoutes {
+	return &ImportRoutes{
+		importHandler: importHandler,
+		middleware:    middleware,
+		customError:   customError,
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type ListRoutes struct {
+	listHandler *handlers.ListHandler
+	middleware  *middleware.Middleware
+}
+
+func (l *ListRoutes) ListRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/lists", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.GetLists))
+	router.HandlerFunc(http.MethodPost, "/v1/users/me/lists", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.CreateList))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/lists/:id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.GetList))
+	router.HandlerFunc(http.MethodPatch, "/v1/users/me/lists/:id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.UpdateList))
+	router.HandlerFunc(http.MethodDelete, "/v1/users/me/lists/:id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.DeleteList))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/lists/:id/movies", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.GetListItems))
+	router.HandlerFunc(http.MethodPost, "/v1/users/me/lists/:id/movies", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.AddListItem))
+	router.HandlerFunc(http.MethodPatch, "/v1/users/me/lists/:id/movies/:movie_id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.UpdateListItem))
+	router.HandlerFunc(http.MethodDelete, "/v1/users/me/lists/:id/movies/:movie_id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.RemoveListItem))
+
+	router.HandlerFunc(http.MethodGet, "/v1/lists/:id", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.GetPublicList))
+	router.HandlerFunc(http.MethodGet, "/v1/lists/:id/movies", l.middleware.RequirePermission(domain.PermissionMoviesRead, l.listHandler.GetPublicListItems))
+}
+
+func NewListRoutes(listHandler *handlers.ListHandler, middleware *middleware.Middleware) *ListRoutes {
+	return &ListRoutes{
+		listHandler: listHandler,
+		middleware:  middleware,
+	}
+}
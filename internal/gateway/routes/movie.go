@@ -2,24 +2,35 @@ package routes
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
 	"net/http"
 )
 
 type MovieRoutes struct {
 	movieHandler *handlers.MovieHandler
+	middleware   *middleware.Middleware
 }
 
 func (m *MovieRoutes) MovieRoute(router *httprouter.Router) {
-	router.HandlerFunc(http.MethodPost, "/v1/movies", m.movieHandler.CreateMovie)
-	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", m.movieHandler.GetMovieById)
-	router.HandlerFunc(http.MethodGet, "/v1/movies", m.movieHandler.GetMovies)
-	router.HandlerFunc(http.MethodPatch, "/v1/movies/:id", m.movieHandler.UpdateMovie)
-	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id", m.movieHandler.DeleteMovie)
+	router.HandlerFunc(http.MethodPost, "/v1/movies", m.middleware.RequirePermission(domain.PermissionMoviesWrite, m.movieHandler.CreateMovie))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", subResources(
+		m.middleware.RequirePermission(domain.PermissionMoviesRead, m.movieHandler.GetMovieById),
+		map[string]http.HandlerFunc{
+			"suggest": m.middleware.RequirePermission(domain.PermissionMoviesRead, m.movieHandler.SuggestMovies),
+			"export":  m.middleware.RequirePermission(domain.PermissionMoviesRead, m.movieHandler.ExportMovies),
+		},
+	))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/similar", m.middleware.RequirePermission(domain.PermissionMoviesRead, m.movieHandler.GetSimilarMovies))
+	router.HandlerFunc(http.MethodGet, "/v1/movies", m.middleware.RequirePermission(domain.PermissionMoviesRead, m.movieHandler.GetMovies))
+	router.HandlerFunc(http.MethodPatch, "/v1/movies/:id", m.middleware.RequirePermission(domain.PermissionMoviesWrite, m.movieHandler.UpdateMovie))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id", m.middleware.RequirePermission(domain.PermissionMoviesWrite, m.movieHandler.DeleteMovie))
 }
 
-func NewMovieRoutes(movieHandler *handlers.MovieHandler) *MovieRoutes {
+func NewMovieRoutes(movieHandler *handlers.MovieHandler, middleware *middleware.Middleware) *MovieRoutes {
 	return &MovieRoutes{
 		movieHandler: movieHandler,
+		middleware:   middleware,
 	}
 }
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type PersonRoutes struct {
+	personHandler *handlers.PersonHandler
+	creditHandler *handlers.CreditHandler
+	middleware    *middleware.Middleware
+}
+
+func (p *PersonRoutes) PersonRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, "/v1/people", p.middleware.RequirePermission(domain.PermissionMoviesWrite, p.personHandler.CreatePerson))
+	router.HandlerFunc(http.MethodGet, "/v1/people/:id", p.middleware.RequirePermission(domain.PermissionMoviesRead, p.personHandler.GetPersonById))
+	router.HandlerFunc(http.MethodGet, "/v1/people", p.middleware.RequirePermission(domain.PermissionMoviesRead, p.personHandler.GetPeople))
+	router.HandlerFunc(http.MethodPatch, "/v1/people/:id", p.middleware.RequirePermission(domain.PermissionMoviesWrite, p.personHandler.UpdatePerson))
+	router.HandlerFunc(http.MethodDelete, "/v1/people/:id", p.middleware.RequirePermission(domain.PermissionMoviesWrite, p.personHandler.DeletePerson))
+	router.HandlerFunc(http.MethodGet, "/v1/people/:id/filmography", p.middleware.RequirePermission(domain.PermissionMoviesRead, p.creditHandler.GetFilmography))
+
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/credits", p.middleware.RequirePermission(domain.PermissionMoviesRead, p.creditHandler.GetMovieCredits))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/credits", p.middleware.RequirePermission(domain.PermissionMoviesWrite, p.creditHandler.CreateCredit))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/credits/:credit_id", p.middleware.RequirePermission(domain.PermissionMoviesWrite, p.creditHandler.DeleteCredit))
+}
+
+func NewPersonRoutes(personHandler *handlers.PersonHandler, creditHandler *handlers.CreditHandler, middleware *middleware.Middleware) *PersonRoutes {
+	return &PersonRoutes{
+		personHandler: personHandler,
+		creditHandler: creditHandler,
+		middleware:    middleware,
+	}
+}
@@ -8,11 +8,18 @@ import (
 )
 
 type Register struct {
-	customError  *helper.CustomError
-	middleware   *middleware.Middleware
-	healthRoutes *HealthRoutes
-	movieRoutes  *MovieRoutes
-	userRoutes   *UserRoutes
+	customError   *helper.CustomError
+	middleware    *middleware.Middleware
+	healthRoutes  *HealthRoutes
+	movieRoutes   *MovieRoutes
+	userRoutes    *UserRoutes
+	tokenRoutes   *TokenRoutes
+	genreRoutes   *GenreRoutes
+	personRoutes  *PersonRoutes
+	reviewRoutes  *ReviewRoutes
+	listRoutes    *ListRoutes
+	artworkRoutes *ArtworkRoutes
+	importRoutes  *ImportRoutes
 }
 
 type Options func(*Register)
@@ -47,6 +54,48 @@ func WithUserRoutes(userRoutes *UserRoutes) Options {
 	}
 }
 
+func WithTokenRoutes(tokenRoutes *TokenRoutes) Options {
+	return func(r *Register) {
+		r.tokenRoutes = tokenRoutes
+	}
+}
+
+func WithGenreRoutes(genreRoutes *GenreRoutes) Options {
+	return func(r *Register) {
+		r.genreRoutes = genreRoutes
+	}
+}
+
+func WithPersonRoutes(personRoutes *PersonRoutes) Options {
+	return func(r *Register) {
+		r.personRoutes = personRoutes
+	}
+}
+
+func WithReviewRoutes(reviewRoutes *ReviewRoutes) Options {
+	return func(r *Register) {
+		r.reviewRoutes = reviewRoutes
+	}
+}
+
+func WithListRoutes(listRoutes *ListRoutes) Options {
+	return func(r *Register) {
+		r.listRoutes = listRoutes
+	}
+}
+
+func WithArtworkRoutes(artworkRoutes *ArtworkRoutes) Options {
+	return func(r *Register) {
+		r.artworkRoutes = artworkRoutes
+	}
+}
+
+func WithImportRoutes(importRoutes *ImportRoutes) Options {
+	return func(r *Register) {
+		r.importRoutes = importRoutes
+	}
+}
+
 func (r *Register) RegisterRoutes() http.Handler {
 	router := httprouter.New()
 	router.NotFound = http.HandlerFunc(r.customError.NotFoundResponse)
@@ -55,8 +104,15 @@ func (r *Register) RegisterRoutes() http.Handler {
 	r.healthRoutes.HealthRoute(router)
 	r.movieRoutes.MovieRoute(router)
 	r.userRoutes.UserRoutes(router)
+	r.tokenRoutes.TokenRoutes(router)
+	r.genreRoutes.GenreRoute(router)
+	r.personRoutes.PersonRoute(router)
+	r.reviewRoutes.ReviewRoute(router)
+	r.listRoutes.ListRoute(router)
+	r.artworkRoutes.ArtworkRoute(router)
+	r.importRoutes.ImportRoute(router)
 
-	return r.middleware.RecoverPanic(r.middleware.RateLimit(router))
+	return r.middleware.RecoverPanic(r.middleware.RateLimit(r.middleware.ReadYourWrites(r.middleware.Authenticate(router))))
 }
 
 func NewRegister(opts ...Options) *Register {
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
+	"net/http"
+)
+
+type ReviewRoutes struct {
+	reviewHandler *handlers.ReviewHandler
+	middleware    *middleware.Middleware
+}
+
+// ReviewRoute registers the review endpoints. A user has at most one review
+// per movie, so the writes act on the caller's own review and need no id.
+func (rr *ReviewRoutes) ReviewRoute(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/reviews", rr.middleware.RequirePermission(domain.PermissionMoviesRead, rr.reviewHandler.GetReviews))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/reviews", rr.middleware.RequirePermission(domain.PermissionMoviesRead, rr.reviewHandler.CreateReview))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/reviews", rr.middleware.RequirePermission(domain.PermissionMoviesRead, rr.reviewHandler.UpdateReview))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/reviews", rr.middleware.RequirePermission(domain.PermissionMoviesRead, rr.reviewHandler.DeleteReview))
+}
+
+func NewReviewRoutes(reviewHandler *handlers.ReviewHandler, middleware *middleware.Middleware) *ReviewRoutes {
+	return &ReviewRoutes{
+		reviewHandler: reviewHandler,
+		middleware:    middleware,
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"net/http"
+)
+
+type TokenRoutes struct {
+	tokenHandler *handlers.TokenHandler
+}
+
+func (t *TokenRoutes) TokenRoutes(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/authentication", t.tokenHandler.CreateAuthenticationToken)
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/activation", t.tokenHandler.CreateActivationToken)
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/password-reset", t.tokenHandler.CreatePasswordResetToken)
+}
+
+func NewTokenRoutes(tokenHandler *handlers.TokenHandler) *TokenRoutes {
+	return &TokenRoutes{
+		tokenHandler: tokenHandler,
+	}
+}
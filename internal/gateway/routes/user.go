@@ -3,20 +3,27 @@ package routes
 import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/middleware"
 	"net/http"
 )
 
 type UserRoutes struct {
 	userHandler *handlers.UserHandler
+	middleware  *middleware.Middleware
 }
 
 func (u *UserRoutes) UserRoutes(router *httprouter.Router) {
 	router.HandlerFunc(http.MethodPost, "/v1/users", u.userHandler.CreateUser)
 	router.HandlerFunc(http.MethodPut, "/v1/users/activated", u.userHandler.ActivateUser)
+	router.HandlerFunc(http.MethodPut, "/v1/users/password", u.userHandler.UpdateUserPassword)
+	router.HandlerFunc(http.MethodPut, "/v1/users/email/confirm", u.userHandler.ConfirmEmailChange)
+	router.HandlerFunc(http.MethodGet, "/v1/users/me", u.middleware.RequireAuthenticatedUser(u.userHandler.GetCurrentUser))
+	router.HandlerFunc(http.MethodPatch, "/v1/users/me", u.middleware.RequireAuthenticatedUser(u.userHandler.UpdateCurrentUser))
 }
 
-func NewUserRoutes(userHandler *handlers.UserHandler) *UserRoutes {
+func NewUserRoutes(userHandler *handlers.UserHandler, middleware *middleware.Middleware) *UserRoutes {
 	return &UserRoutes{
 		userHandler: userHandler,
+		middleware:  middleware,
 	}
 }
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorCodec turns pagination cursors into opaque strings and back. The
+// payload is signed so clients can't hand-craft a cursor into the query.
+type CursorCodec struct {
+	secret []byte
+}
+
+func (c *CursorCodec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *CursorCodec) Encode(cursor dto.Cursor) (string, error) {
+	js, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(js)
+	return payload + "." + c.sign(payload), nil
+}
+
+func (c *CursorCodec) Decode(s string) (*dto.Cursor, error) {
+	payload, signature, found := strings.Cut(s, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(c.sign(payload))) {
+		return nil, ErrInvalidCursor
+	}
+
+	js, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor dto.Cursor
+	if err := json.Unmarshal(js, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{
+		secret: secret,
+	}
+}
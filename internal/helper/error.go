@@ -1,9 +1,12 @@
 package helper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
 type CustomError struct {
@@ -28,11 +31,22 @@ func (c *CustomError) ErrorResponse(w http.ResponseWriter, r *http.Request, stat
 }
 
 func (c *CustomError) ServerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.TimeoutResponse(w, r, err)
+		return
+	}
+
 	c.LogError(r, err)
 	message := "the server encountered a problem and could not process your request"
 	c.ErrorResponse(w, r, http.StatusInternalServerError, message)
 }
 
+func (c *CustomError) TimeoutResponse(w http.ResponseWriter, r *http.Request, err error) {
+	c.LogError(r, err)
+	message := "the server took too long to process your request, please try again"
+	c.ErrorResponse(w, r, http.StatusGatewayTimeout, message)
+}
+
 func (c *CustomError) NotFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "the requested resource could not be found"
 	c.ErrorResponse(w, r, http.StatusNotFound, message)
@@ -47,6 +61,11 @@ func (c *CustomError) BadRequestResponse(w http.ResponseWriter, r *http.Request,
 	c.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
 }
 
+func (c *CustomError) UnsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, mediaTypes ...string) {
+	message := fmt.Sprintf("the Content-Type header must be one of: %s", strings.Join(mediaTypes, ", "))
+	c.ErrorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
 func (c *CustomError) FailedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
 	c.ErrorResponse(w, r, http.StatusUnprocessableEntity, errors)
 }
@@ -56,11 +75,52 @@ func (c *CustomError) EditConflictResponse(w http.ResponseWriter, r *http.Reques
 	c.ErrorResponse(w, r, http.StatusConflict, message)
 }
 
+func (c *CustomError) PreconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the record has changed since you last fetched it, fetch it again and retry"
+	c.ErrorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
 func (c *CustomError) RateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
 	message := "rate limit exceeded, please try again"
 	c.ErrorResponse(w, r, http.StatusTooManyRequests, message)
 }
 
+func (c *CustomError) InvalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid authentication credentials"
+	c.ErrorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (c *CustomError) InvalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	message := "invalid or missing authentication token"
+	c.ErrorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (c *CustomError) AuthenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you must be authenticated to access this resource"
+	c.ErrorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (c *CustomError) InactiveAccountResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account must be activated to access this resource"
+	c.ErrorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (c *CustomError) NotPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	c.ErrorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (c *CustomError) GenreInUseResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the genre is still assigned to one or more movies"
+	c.ErrorResponse(w, r, http.StatusConflict, message)
+}
+
+func (c *CustomError) BuiltInListResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the watchlist and watched list cannot be deleted"
+	c.ErrorResponse(w, r, http.StatusConflict, message)
+}
+
 func NewCustomErr(logger *slog.Logger) *CustomError {
 	return &CustomError{
 		logger: logger,
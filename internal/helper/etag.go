@@ -0,0 +1,83 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag builds the entity tag of a versioned record as "id-version-digest".
+// The digest covers the record's JSON representation, so fields that change
+// without bumping the version (such as a movie's rating or artwork) still
+// change the tag that If-None-Match is compared with. If-Match only looks at
+// the id and version; see IfMatchVersion.
+func ETag(id int64, version int32, representation any) (string, error) {
+	js, err := json.Marshal(representation)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(js)
+	return fmt.Sprintf(`"%d-%d-%s"`, id, version, hex.EncodeToString(digest[:8])), nil
+}
+
+// etags splits an If-Match or If-None-Match header into its entity tags.
+func etags(header string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// NoneMatch reports whether the request's If-None-Match header matches etag,
+// in which case a GET should be answered with 304 Not Modified. Tags are
+// compared weakly, as RFC 9110 requires for If-None-Match.
+func NoneMatch(r *http.Request, etag string) bool {
+	for _, tag := range etags(r.Header.Get("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatchVersion reads the version the client expects record id to be at
+// from the If-Match header. It returns 0 when there is no header or it is
+// "*", meaning any version will do. ok is false when the header names no
+// strong tag of this record (or several versions of it), which can only
+// fail the precondition. The digest part of the tag is ignored: a record
+// whose rating changed since it was fetched hasn't been edited.
+func IfMatchVersion(r *http.Request, id int64) (version int32, ok bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return 0, true
+	}
+
+	prefix := fmt.Sprintf(`"%d-`, id)
+	for _, tag := range etags(header) {
+		if tag == "*" {
+			return 0, true
+		}
+
+		value, found := strings.CutPrefix(tag, prefix)
+		if !found || !strings.HasSuffix(value, `"`) {
+			continue
+		}
+
+		value, _, _ = strings.Cut(strings.TrimSuffix(value, `"`), "-")
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || v < 1 || (version != 0 && int32(v) != version) {
+			return 0, false
+		}
+		version = int32(v)
+	}
+
+	return version, version != 0
+}
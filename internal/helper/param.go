@@ -1,17 +1,21 @@
 package helper
 
 import (
-	"errors"
+	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 	"strconv"
 )
 
 func ReadIdParam(r *http.Request) (int64, error) {
+	return ReadIdParamByName(r, "id")
+}
+
+func ReadIdParamByName(r *http.Request, name string) (int64, error) {
 	param := httprouter.ParamsFromContext(r.Context())
-	id, err := strconv.ParseInt(param.ByName("id"), 10, 64)
+	id, err := strconv.ParseInt(param.ByName(name), 10, 64)
 	if err != nil || id < 1 {
-		return 0, errors.New("invalid id parameter")
+		return 0, fmt.Errorf("invalid %s parameter", name)
 	}
 	return id, nil
 }
@@ -37,3 +37,17 @@ func ReadInt(qs url.Values, key string, defaultValue int, v *validator.Validator
 	}
 	return i
 }
+
+func ReadBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+	return b
+}
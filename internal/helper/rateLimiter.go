@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter hands out a token bucket per key (an IP address, an email, ...)
+// and forgets keys that haven't been seen for a while.
+type RateLimiter struct {
+	mu      sync.Mutex
+	clients map[string]*client
+	limit   rate.Limit
+	burst   int
+}
+
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, found := l.clients[key]; !found {
+		l.clients[key] = &client{
+			limiter: rate.NewLimiter(l.limit, l.burst),
+		}
+	}
+
+	l.clients[key].lastSeen = time.Now()
+
+	return l.clients[key].limiter.Allow()
+}
+
+func (l *RateLimiter) cleanup(interval, maxIdle time.Duration) {
+	for {
+		time.Sleep(interval)
+
+		l.mu.Lock()
+		for key, c := range l.clients {
+			if time.Since(c.lastSeen) > maxIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func NewRateLimiter(limit rate.Limit, burst int, maxIdle time.Duration) *RateLimiter {
+	l := &RateLimiter{
+		clients: make(map[string]*client),
+		limit:   limit,
+		burst:   burst,
+	}
+	go l.cleanup(time.Minute, maxIdle)
+	return l
+}
@@ -0,0 +1,353 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	// fileBatchSize is how many valid rows are gathered before they are
+	// stored, unless the whole file is imported atomically.
+	fileBatchSize = 500
+
+	maxNDJSONLineSize = 1 << 20
+	genreSeparator    = "|"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrInvalidFile   = errors.New("invalid file")
+	// ErrIncomplete is returned, along with the result so far, when some of
+	// a file was stored before an error stopped the rest.
+	ErrIncomplete = errors.New("import incomplete")
+)
+
+// csvColumns are the columns a CSV file may have, in no particular order.
+// The header row names them; all but the external ids are required.
+var csvColumns = []string{"title", "year", "runtime", "genres", "imdb_id", "tmdb_id"}
+
+// csvIgnoredColumns are columns of an export that a movie can't be created
+// with. They are allowed, and skipped, so an export can be imported as it is.
+var csvIgnoredColumns = []string{"id", "rating", "rating_count", "version"}
+
+// LineError holds what was wrong with one row of a file, keyed by field as
+// validation errors are. Problems with the row as a whole use "row".
+type LineError struct {
+	Line   int               `json:"line"`
+	Errors map[string]string `json:"errors"`
+}
+
+type FileResult struct {
+	Created []int64     `json:"created"`
+	Errors  []LineError `json:"errors"`
+}
+
+// rowFunc is called for every row read from a file, with either the movie
+// or what made the row unreadable.
+type rowFunc func(line int, input *dto.Movie, errs map[string]string) error
+
+// ImportFile reads movies from a CSV or NDJSON file and creates them. Rows
+// are validated like POST /v1/movies and the valid ones stored in batches,
+// so a bad row only costs itself. With atomic set, nothing is stored unless
+// every row is valid. Errors about the file as a whole wrap ErrInvalidFile.
+//
+// Without atomic, batches stored before a failure stay stored. The result so
+// far is then returned with an error wrapping ErrIncomplete, and the rows
+// that were read but not stored are reported in it. After a batch fails to
+// store, the rest of the file is still read so its rows are reported too.
+func (i *importer) ImportFile(ctx context.Context, r io.Reader, format string, atomic bool) (*FileResult, error) {
+	catalogue, err := i.genreService.Catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FileResult{Created: []int64{}, Errors: []LineError{}}
+
+	var pending []*dto.Movie
+	var lines []int
+	var failed error
+
+	store := func() error {
+		var movies []*domain.Movie
+		var conflicts map[int]error
+
+		switch {
+		case atomic:
+			if movies, conflicts, err = i.movieService.CreateMovies(ctx, pending, true); err != nil {
+				return err
+			}
+		case failed == nil:
+			movies, conflicts, failed = i.createBatch(ctx, pending)
+		}
+
+		for j, line := range lines {
+			switch {
+			case j < len(movies) && movies[j] != nil:
+				result.Created = append(result.Created, movies[j].Id)
+			case conflicts[j] != nil:
+				field := "tmdb_id"
+				if errors.Is(conflicts[j], repository.ErrDuplicateImdbId) {
+					field = "imdb_id"
+				}
+				result.Errors = append(result.Errors, LineError{Line: line, Errors: map[string]string{field: "is already used by another movie"}})
+			case failed != nil:
+				result.Errors = append(result.Errors, LineError{Line: line, Errors: map[string]string{"row": "was not stored, import it again"}})
+			}
+		}
+
+		pending, lines = nil, nil
+		return nil
+	}
+
+	err = readMovies(r, format, func(line int, input *dto.Movie, errs map[string]string) error {
+		if errs == nil {
+			v := validator.NewValidator()
+			dto.ValidateMovie(v, input, catalogue)
+			errs = v.Errors
+		}
+
+		if len(errs) > 0 {
+			result.Errors = append(result.Errors, LineError{Line: line, Errors: errs})
+			return nil
+		}
+
+		pending = append(pending, input)
+		lines = append(lines, line)
+
+		if !atomic && len(pending) == fileBatchSize {
+			return store()
+		}
+		return nil
+	})
+	if err != nil {
+		// Nothing has been stored yet, so the file can simply be sent again.
+		if atomic || (failed == nil && len(result.Created) == 0) {
+			return nil, err
+		}
+		failed = cmp.Or(failed, err)
+	}
+
+	if !atomic || len(result.Errors) == 0 {
+		if err = store(); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(result.Errors, func(a, b LineError) int {
+		return a.Line - b.Line
+	})
+
+	if failed != nil {
+		return result, fmt.Errorf("%w: %w", ErrIncomplete, failed)
+	}
+
+	return result, nil
+}
+
+// createBatch stores a batch of a non-atomic import. A movie created with one
+// of the batch's ids after they were checked fails the whole statement, so
+// the batch is then retried a movie at a time and only that one is left out.
+func (i *importer) createBatch(ctx context.Context, inputs []*dto.Movie) ([]*domain.Movie, map[int]error, error) {
+	movies, conflicts, err := i.movieService.CreateMovies(ctx, inputs, false)
+	if !isDuplicate(err) {
+		return movies, conflicts, err
+	}
+
+	movies = make([]*domain.Movie, len(inputs))
+	conflicts = make(map[int]error)
+	for j := range inputs {
+		created, conflict, err := i.movieService.CreateMovies(ctx, inputs[j:j+1], false)
+		switch {
+		case isDuplicate(err):
+			conflicts[j] = err
+		case err != nil:
+			return movies, conflicts, err
+		case conflict[0] != nil:
+			conflicts[j] = conflict[0]
+		default:
+			movies[j] = created[0]
+		}
+	}
+	return movies, conflicts, nil
+}
+
+func isDuplicate(err error) bool {
+	return errors.Is(err, repository.ErrDuplicateImdbId) || errors.Is(err, repository.ErrDuplicateTmdbId)
+}
+
+func readMovies(r io.Reader, format string, fn rowFunc) error {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, fn)
+	case FormatNDJSON:
+		return readNDJSON(r, fn)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// readCSV reads a file whose first row names the columns. Genres are given
+// in a single field, separated by "|".
+func readCSV(r io.Reader, fn rowFunc) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil
+	case err != nil:
+		return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if slices.Contains(csvIgnoredColumns, name) {
+			continue
+		}
+		if !slices.Contains(csvColumns, name) {
+			return fmt.Errorf("%w: unknown column %q, columns must be %s", ErrInvalidFile, name, strings.Join(csvColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return fmt.Errorf("%w: column %q appears more than once", ErrInvalidFile, name)
+		}
+		columns[name] = index
+	}
+
+	for _, name := range csvColumns[:4] {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: missing column %q", ErrInvalidFile, name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		switch {
+		case errors.Is(err, csv.ErrFieldCount):
+			err = fn(line, nil, map[string]string{"row": fmt.Sprintf("must have %d fields", len(header))})
+		case err != nil:
+			return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		default:
+			input, errs := parseCSVRecord(record, columns)
+			err = fn(line, input, errs)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func parseCSVRecord(record []string, columns map[string]int) (*dto.Movie, map[string]string) {
+	errs := make(map[string]string)
+
+	field := func(name string) string {
+		index, ok := columns[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	integer := func(name string, bitSize int) int64 {
+		value := field(name)
+		if value == "" {
+			return 0
+		}
+		i, err := strconv.ParseInt(value, 10, bitSize)
+		if err != nil {
+			errs[name] = "must be an integer value"
+		}
+		return i
+	}
+
+	input := &dto.Movie{
+		Title:   field("title"),
+		Year:    int32(integer("year", 32)),
+		Runtime: int32(integer("runtime", 32)),
+		Genres:  []string{},
+		ImdbId:  field("imdb_id"),
+		TmdbId:  integer("tmdb_id", 64),
+	}
+
+	for _, genre := range strings.Split(field("genres"), genreSeparator) {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			input.Genres = append(input.Genres, genre)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return input, nil
+}
+
+// readNDJSON reads one movie, shaped like the body of POST /v1/movies, per
+// line. Blank lines are skipped.
+func readNDJSON(r io.Reader, fn rowFunc) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var input *dto.Movie
+		var errs map[string]string
+
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch err := dec.Decode(&input); {
+		case errors.As(err, &unmarshalTypeError) && unmarshalTypeError.Field != "":
+			errs = map[string]string{unmarshalTypeError.Field: "has the wrong type"}
+		case err != nil && strings.HasPrefix(err.Error(), "json: unknown field "):
+			errs = map[string]string{"row": "contains " + strings.TrimPrefix(err.Error(), "json: ")}
+		case err != nil, input == nil, dec.More():
+			errs = map[string]string{"row": "must be a single JSON object"}
+		}
+
+		if err := fn(line, input, errs); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: line %d is longer than %d bytes", ErrInvalidFile, line+1, maxNDJSONLineSize)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/exporter"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ndjsonMovies returns n valid NDJSON rows with TMDB ids 1 to n.
+func ndjsonMovies(n int) string {
+	var b strings.Builder
+	for id := 1; id <= n; id++ {
+		fmt.Fprintf(&b, `{"title": "Movie %d", "year": 2000, "runtime": 90, "genres": ["action"], "tmdb_id": %d}`+"\n", id, id)
+	}
+	return b.String()
+}
+
+func TestImportFileReportsUnstoredRows(t *testing.T) {
+	imp, movies := newTestImporter(t)
+	movies.createMoviesErrs = []error{nil, errors.New("connection reset")}
+
+	rows := 2*fileBatchSize + 10
+	result, err := imp.ImportFile(context.Background(), strings.NewReader(ndjsonMovies(rows)), FormatNDJSON, false)
+	if !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("ImportFile error = %v, want ErrIncomplete", err)
+	}
+	if result == nil {
+		t.Fatal("ImportFile returned no result with ErrIncomplete")
+	}
+
+	if len(result.Created) != fileBatchSize {
+		t.Errorf("%d movies created, want %d", len(result.Created), fileBatchSize)
+	}
+	if want := rows - fileBatchSize; len(result.Errors) != want {
+		t.Fatalf("%d rows reported, want %d", len(result.Errors), want)
+	}
+	for j, lineError := range result.Errors {
+		if want := fileBatchSize + j + 1; lineError.Line != want {
+			t.Fatalf("error %d is for line %d, want %d", j, lineError.Line, want)
+		}
+		if _, ok := lineError.Errors["row"]; !ok {
+			t.Fatalf("line %d errors = %v, want a row error", lineError.Line, lineError.Errors)
+		}
+	}
+}
+
+func TestImportFileRetriesBatchRowByRow(t *testing.T) {
+	imp, movies := newTestImporter(t)
+
+	// Movie 3 appears after the batch's ids were checked, so the batch
+	// insert fails as a whole.
+	movies.createMoviesErrs = []error{repository.ErrDuplicateTmdbId}
+	movies.CreateMovie(context.Background(), &dto.Movie{Title: "Movie 3", Year: 2000, Runtime: 90, Genres: []string{"action"}, TmdbId: 3})
+
+	result, err := imp.ImportFile(context.Background(), strings.NewReader(ndjsonMovies(5)), FormatNDJSON, false)
+	if err != nil {
+		t.Fatalf("ImportFile returned error: %v", err)
+	}
+
+	if len(result.Created) != 4 {
+		t.Errorf("%d movies created, want 4", len(result.Created))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 3 || result.Errors[0].Errors["tmdb_id"] == "" {
+		t.Errorf("errors = %+v, want a tmdb_id error on line 3", result.Errors)
+	}
+}
+
+func TestImportFileReadsExportedCSV(t *testing.T) {
+	var export bytes.Buffer
+	w, err := exporter.NewWriter(&export, exporter.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := &domain.Movie{Id: 7, Title: "The Matrix", Year: 1999, Runtime: 136, Genres: []string{"action", "science-fiction"}, Rating: 8.7, RatingCount: 3, TmdbId: 603, Version: 4}
+	if err = w.Write(exported); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	imp, movies := newTestImporter(t)
+
+	result, err := imp.ImportFile(context.Background(), &export, FormatCSV, false)
+	if err != nil {
+		t.Fatalf("ImportFile returned error: %v", err)
+	}
+	if len(result.Created) != 1 || len(result.Errors) != 0 {
+		t.Fatalf("result = %+v, want one movie created and no errors", result)
+	}
+
+	movie := movies.movies[result.Created[0]]
+	if movie.Title != exported.Title || movie.TmdbId != exported.TmdbId || !reflect.DeepEqual(movie.Genres, exported.Genres) {
+		t.Errorf("imported %+v, want it to match the exported movie", movie)
+	}
+}
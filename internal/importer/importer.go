@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const maxImportedGenres = 5
+
+// InvalidMetadataError is returned when what a provider has on a movie does
+// not pass dto.ValidateMovie, for example when it has no release date yet.
+type InvalidMetadataError struct {
+	Errors map[string]string
+}
+
+func (e *InvalidMetadataError) Error() string {
+	fields := slices.Sorted(maps.Keys(e.Errors))
+	return fmt.Sprintf("invalid metadata: %s", strings.Join(fields, ", "))
+}
+
+type Result struct {
+	Movie         *domain.Movie
+	Created       bool
+	SkippedGenres []string
+}
+
+type Importer interface {
+	Import(ctx context.Context, provider, id string) (*Result, error)
+	ImportFile(ctx context.Context, r io.Reader, format string, atomic bool) (*FileResult, error)
+	Providers() []string
+}
+
+type importer struct {
+	movieService service.MovieService
+	genreService service.GenreService
+	providers    map[string]MetadataProvider
+}
+
+// Import fetches a movie from the named provider and stores it. A movie
+// already imported (matched on its TMDB id) is updated in place, so
+// importing the same id twice is safe. Genres the catalogue doesn't know are
+// left out and reported in the result rather than failing the import.
+func (i *importer) Import(ctx context.Context, provider, id string) (*Result, error) {
+	metadataProvider, ok := i.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	metadata, err := metadataProvider.FetchMovie(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue, err := i.genreService.Catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+
+	genres := []string{}
+	for _, genre := range metadata.Genres {
+		slug, ok := catalogue.Resolve(genre)
+		switch {
+		case !ok, len(genres) == maxImportedGenres:
+			result.SkippedGenres = append(result.SkippedGenres, genre)
+		case !slices.Contains(genres, slug):
+			genres = append(genres, slug)
+		}
+	}
+
+	input := &dto.Movie{
+		Title:   metadata.Title,
+		Year:    metadata.Year,
+		Runtime: metadata.Runtime,
+		Genres:  genres,
+		ImdbId:  metadata.ImdbId,
+		TmdbId:  metadata.TmdbId,
+	}
+
+	v := validator.NewValidator()
+	dto.ValidateMovie(v, input, catalogue)
+	if !v.Valid() {
+		return nil, &InvalidMetadataError{Errors: v.Errors}
+	}
+
+	result.Movie, result.Created, err = i.store(ctx, input)
+	// A movie with the same TMDB id may have been created since it was looked
+	// up, by a concurrent import or by hand; it is updated instead. It is
+	// looked up on the primary, as a replica may not have the row yet.
+	if errors.Is(err, repository.ErrDuplicateTmdbId) {
+		result.Movie, result.Created, err = i.store(repository.WithPrimaryReads(ctx), input)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// store creates the movie, or updates the one already carrying its TMDB id.
+func (i *importer) store(ctx context.Context, input *dto.Movie) (*domain.Movie, bool, error) {
+	existing, err := i.movieService.GetMovieByTmdbId(ctx, input.TmdbId)
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound):
+		movie, err := i.movieService.CreateMovie(ctx, input)
+		return movie, true, err
+	case err != nil:
+		return nil, false, err
+	}
+
+	update := &dto.UpdateMovie{
+		Title:   &input.Title,
+		Year:    &input.Year,
+		Runtime: &input.Runtime,
+		Genres:  input.Genres,
+	}
+	if input.ImdbId != "" {
+		update.ImdbId = &input.ImdbId
+	}
+
+	movie, err := i.movieService.UpdateMovie(ctx, existing.Id, update, existing.Version)
+	return movie, false, err
+}
+
+func (i *importer) Providers() []string {
+	return slices.Sorted(maps.Keys(i.providers))
+}
+
+func NewImporter(movieService service.MovieService, genreService service.GenreService, providers map[string]MetadataProvider) Importer {
+	return &importer{
+		movieService: movieService,
+		genreService: genreService,
+		providers:    providers,
+	}
+}
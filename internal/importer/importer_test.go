@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/service"
+	"reflect"
+	"testing"
+)
+
+var testCatalogue = domain.NewGenreCatalogue([]*domain.Genre{
+	{Slug: "action", Name: "Action"},
+	{Slug: "science-fiction", Name: "Science Fiction", Aliases: []string{"Sci-Fi"}},
+})
+
+// movieStore keeps movies in memory for the MovieService calls the importer
+// makes; anything else panics on the nil interface.
+type movieStore struct {
+	service.MovieService
+	movies  map[int64]*domain.Movie
+	updates int
+
+	// createErr, when set, is returned by the next CreateMovie, which first
+	// stores the movie as a concurrent writer would have.
+	createErr error
+
+	// createMoviesErrs are returned by successive CreateMovies calls; a nil
+	// entry, or running out of them, lets the call succeed.
+	createMoviesErrs []error
+}
+
+func (s *movieStore) GetMovieByTmdbId(_ context.Context, tmdbId int64) (*domain.Movie, error) {
+	for _, movie := range s.movies {
+		if movie.TmdbId == tmdbId {
+			copied := *movie
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (s *movieStore) CreateMovie(_ context.Context, input *dto.Movie) (*domain.Movie, error) {
+	movie := &domain.Movie{
+		Id:      int64(len(s.movies) + 1),
+		Title:   input.Title,
+		Year:    input.Year,
+		Runtime: input.Runtime,
+		Genres:  input.Genres,
+		ImdbId:  input.ImdbId,
+		TmdbId:  input.TmdbId,
+		Version: 1,
+	}
+	s.movies[movie.Id] = movie
+
+	if err := s.createErr; err != nil {
+		s.createErr = nil
+		return nil, err
+	}
+	return movie, nil
+}
+
+func (s *movieStore) CreateMovies(ctx context.Context, inputs []*dto.Movie, _ bool) ([]*domain.Movie, map[int]error, error) {
+	if len(s.createMoviesErrs) > 0 {
+		err := s.createMoviesErrs[0]
+		s.createMoviesErrs = s.createMoviesErrs[1:]
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	movies := make([]*domain.Movie, len(inputs))
+	conflicts := make(map[int]error)
+	for j, input := range inputs {
+		if _, err := s.GetMovieByTmdbId(ctx, input.TmdbId); err == nil {
+			conflicts[j] = repository.ErrDuplicateTmdbId
+			continue
+		}
+		movies[j], _ = s.CreateMovie(ctx, input)
+	}
+	return movies, conflicts, nil
+}
+
+func (s *movieStore) UpdateMovie(_ context.Context, id int64, input *dto.UpdateMovie, version int32) (*domain.Movie, error) {
+	movie, ok := s.movies[id]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	if movie.Version != version {
+		return nil, service.ErrPreconditionFailed
+	}
+
+	movie.Title, movie.Year, movie.Runtime, movie.Genres = *input.Title, *input.Year, *input.Runtime, input.Genres
+	if input.ImdbId != nil {
+		movie.ImdbId = *input.ImdbId
+	}
+	movie.Version++
+	s.updates++
+
+	return movie, nil
+}
+
+type genreCatalogue struct {
+	service.GenreService
+}
+
+func (genreCatalogue) Catalogue(context.Context) (*domain.GenreCatalogue, error) {
+	return testCatalogue, nil
+}
+
+func newTestImporter(t *testing.T) (Importer, *movieStore) {
+	movies := &movieStore{movies: make(map[int64]*domain.Movie)}
+	return NewImporter(movies, genreCatalogue{}, map[string]MetadataProvider{"tmdb": newTestTMDB(t)}), movies
+}
+
+func TestImportCreatesThenUpdates(t *testing.T) {
+	imp, movies := newTestImporter(t)
+
+	first, err := imp.Import(context.Background(), "tmdb", "603")
+	if err != nil {
+		t.Fatalf("first Import returned error: %v", err)
+	}
+	if !first.Created {
+		t.Error("first Import: Created = false, want true")
+	}
+	if want := []string{"action", "science-fiction"}; !reflect.DeepEqual(first.Movie.Genres, want) {
+		t.Errorf("first Import: genres = %v, want %v", first.Movie.Genres, want)
+	}
+	if want := []string{"Cyberpunk"}; !reflect.DeepEqual(first.SkippedGenres, want) {
+		t.Errorf("first Import: skipped genres = %v, want %v", first.SkippedGenres, want)
+	}
+
+	second, err := imp.Import(context.Background(), "tmdb", "tt0133093")
+	if err != nil {
+		t.Fatalf("second Import returned error: %v", err)
+	}
+	if second.Created {
+		t.Error("second Import: Created = true, want false")
+	}
+	if second.Movie.Id != first.Movie.Id {
+		t.Errorf("second Import updated movie %d, want %d", second.Movie.Id, first.Movie.Id)
+	}
+	if len(movies.movies) != 1 || movies.updates != 1 {
+		t.Errorf("after two imports: %d movies and %d updates, want 1 and 1", len(movies.movies), movies.updates)
+	}
+}
+
+func TestImportRetriesConcurrentlyCreatedMovie(t *testing.T) {
+	imp, movies := newTestImporter(t)
+	movies.createErr = repository.ErrDuplicateTmdbId
+
+	result, err := imp.Import(context.Background(), "tmdb", "603")
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if result.Created {
+		t.Error("Created = true, want false for a movie created concurrently")
+	}
+	if movies.updates != 1 {
+		t.Errorf("updates = %d, want 1", movies.updates)
+	}
+}
+
+func TestImportInvalidMetadata(t *testing.T) {
+	imp, movies := newTestImporter(t)
+
+	_, err := imp.Import(context.Background(), "tmdb", "604")
+
+	var invalid *InvalidMetadataError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Import error = %v, want InvalidMetadataError", err)
+	}
+	for _, field := range []string{"year", "genres"} {
+		if _, ok := invalid.Errors[field]; !ok {
+			t.Errorf("InvalidMetadataError.Errors = %v, want an error on %q", invalid.Errors, field)
+		}
+	}
+	if len(movies.movies) != 0 {
+		t.Errorf("%d movies stored, want none", len(movies.movies))
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	imp, _ := newTestImporter(t)
+
+	tests := []struct {
+		provider, id string
+		want         error
+	}{
+		{provider: "imdb", id: "603", want: ErrUnknownProvider},
+		{provider: "tmdb", id: "999", want: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		if _, err := imp.Import(context.Background(), tt.provider, tt.id); !errors.Is(err, tt.want) {
+			t.Errorf("Import(%q, %q) error = %v, want %v", tt.provider, tt.id, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package importer
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound        = errors.New("movie not found at provider")
+	ErrUnknownProvider = errors.New("unknown provider")
+)
+
+// Metadata is a movie as a provider describes it. Genres are the provider's
+// own names and still have to be resolved against the genre catalogue.
+type Metadata struct {
+	Title   string
+	Year    int32
+	Runtime int32
+	Genres  []string
+	ImdbId  string
+	TmdbId  int64
+}
+
+// MetadataProvider looks a movie up in an external database. What id means is
+// up to the provider; ErrNotFound is returned when it doesn't know the movie.
+type MetadataProvider interface {
+	FetchMovie(ctx context.Context, id string) (*Metadata, error)
+}
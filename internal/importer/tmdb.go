@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxTMDBResponseSize = 1 << 20
+
+type tmdbMovie struct {
+	Id          int64  `json:"id"`
+	ImdbId      string `json:"imdb_id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+	Runtime     int32  `json:"runtime"`
+	Genres      []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+}
+
+type tmdbFindResult struct {
+	MovieResults []struct {
+		Id int64 `json:"id"`
+	} `json:"movie_results"`
+}
+
+// TMDB speaks the TMDB v3 API. Movies are fetched by their TMDB id or, for
+// ids starting with "tt", looked up by IMDb id first.
+type TMDB struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func (t *TMDB) FetchMovie(ctx context.Context, id string) (*Metadata, error) {
+	id = strings.TrimSpace(id)
+
+	if strings.HasPrefix(id, "tt") {
+		var found tmdbFindResult
+		if err := t.get(ctx, "/find/"+url.PathEscape(id), url.Values{"external_source": {"imdb_id"}}, &found); err != nil {
+			return nil, err
+		}
+		if len(found.MovieResults) == 0 {
+			return nil, ErrNotFound
+		}
+		id = strconv.FormatInt(found.MovieResults[0].Id, 10)
+	}
+
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, ErrNotFound
+	}
+
+	var movie tmdbMovie
+	if err := t.get(ctx, "/movie/"+id, nil, &movie); err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{
+		Title:   movie.Title,
+		Runtime: movie.Runtime,
+		ImdbId:  movie.ImdbId,
+		TmdbId:  movie.Id,
+	}
+
+	if released, err := time.Parse(time.DateOnly, movie.ReleaseDate); err == nil {
+		metadata.Year = int32(released.Year())
+	}
+
+	for _, genre := range movie.Genres {
+		metadata.Genres = append(metadata.Genres, genre.Name)
+	}
+
+	return metadata, nil
+}
+
+func (t *TMDB) get(ctx context.Context, path string, query url.Values, dst any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if t.apiKey != "" {
+		query.Set("api_key", t.apiKey)
+	}
+
+	endpoint := strings.TrimSuffix(t.baseURL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode != http.StatusOK:
+		return fmt.Errorf("tmdb: unexpected status %s", res.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxTMDBResponseSize)).Decode(dst); err != nil {
+		return fmt.Errorf("tmdb: decoding response: %w", err)
+	}
+	return nil
+}
+
+type TMDBOptions func(*TMDB)
+
+func WithBaseURL(baseURL string) TMDBOptions {
+	return func(t *TMDB) {
+		t.baseURL = baseURL
+	}
+}
+
+func WithAPIKey(apiKey string) TMDBOptions {
+	return func(t *TMDB) {
+		t.apiKey = apiKey
+	}
+}
+
+func WithTimeout(timeout time.Duration) TMDBOptions {
+	return func(t *TMDB) {
+		t.client.Timeout = timeout
+	}
+}
+
+func NewTMDB(options ...TMDBOptions) *TMDB {
+	t := &TMDB{
+		baseURL: "https://api.themoviedb.org/3",
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t
+}
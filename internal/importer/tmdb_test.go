@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAPIKey = "secret"
+
+// newTMDBServer stands in for the TMDB API. It knows The Matrix (603), a
+// movie without a release date (604) and fails with a 500 for id 500.
+func newTMDBServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/3/find/tt0133093":
+			if r.URL.Query().Get("external_source") != "imdb_id" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"movie_results": [{"id": 603}]}`)
+		case "/3/find/tt0000001":
+			fmt.Fprint(w, `{"movie_results": []}`)
+		case "/3/movie/603":
+			fmt.Fprint(w, `{
+				"id": 603,
+				"imdb_id": "tt0133093",
+				"title": "The Matrix",
+				"release_date": "1999-03-30",
+				"runtime": 136,
+				"genres": [{"id": 28, "name": "Action"}, {"id": 878, "name": "Science Fiction"}, {"id": 1, "name": "Cyberpunk"}]
+			}`)
+		case "/3/movie/604":
+			fmt.Fprint(w, `{"id": 604, "title": "Untitled", "release_date": "", "runtime": 90, "genres": []}`)
+		case "/3/movie/500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status_code": 34, "status_message": "The resource you requested could not be found."}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestTMDB(t *testing.T) *TMDB {
+	return NewTMDB(WithBaseURL(newTMDBServer(t).URL+"/3/"), WithAPIKey(testAPIKey))
+}
+
+func TestTMDBFetchMovie(t *testing.T) {
+	matrix := &Metadata{
+		Title:   "The Matrix",
+		Year:    1999,
+		Runtime: 136,
+		Genres:  []string{"Action", "Science Fiction", "Cyberpunk"},
+		ImdbId:  "tt0133093",
+		TmdbId:  603,
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		want *Metadata
+	}{
+		{name: "tmdb id", id: "603", want: matrix},
+		{name: "imdb id", id: "tt0133093", want: matrix},
+		{name: "surrounding spaces", id: " 603 ", want: matrix},
+		{name: "no release date", id: "604", want: &Metadata{Title: "Untitled", Runtime: 90, TmdbId: 604}},
+	}
+
+	tmdb := newTestTMDB(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tmdb.FetchMovie(context.Background(), tt.id)
+			if err != nil {
+				t.Fatalf("FetchMovie(%q) returned error: %v", tt.id, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FetchMovie(%q) = %+v, want %+v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTMDBFetchMovieNotFound(t *testing.T) {
+	tmdb := newTestTMDB(t)
+
+	for _, id := range []string{"999", "tt0000001", "tt9999999", "not-an-id", ""} {
+		if _, err := tmdb.FetchMovie(context.Background(), id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FetchMovie(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+}
+
+func TestTMDBFetchMovieUnexpectedStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		tmdb   *TMDB
+		id     string
+		status string
+	}{
+		{name: "server error", tmdb: newTestTMDB(t), id: "500", status: "500"},
+		{name: "wrong api key", tmdb: NewTMDB(WithBaseURL(newTMDBServer(t).URL+"/3"), WithAPIKey("wrong")), id: "603", status: "401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tmdb.FetchMovie(context.Background(), tt.id)
+			if err == nil || errors.Is(err, ErrNotFound) {
+				t.Fatalf("FetchMovie(%q) error = %v, want an unexpected status error", tt.id, err)
+			}
+			if !strings.Contains(err.Error(), tt.status) {
+				t.Errorf("FetchMovie(%q) error = %q, want it to mention status %s", tt.id, err, tt.status)
+			}
+		})
+	}
+}
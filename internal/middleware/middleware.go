@@ -1,30 +1,37 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"github.com/saleh-ghazimoradi/FilmFetch/config"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/helper"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/validator"
 	"github.com/tomasen/realip"
 	"golang.org/x/time/rate"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 )
 
-type client struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
-}
-
 type Middleware struct {
-	config      *config.Config
-	customError *helper.CustomError
+	config               *config.Config
+	customError          *helper.CustomError
+	tokenRepository      repository.TokenRepository
+	permissionRepository repository.PermissionRepository
 }
 
 func (m *Middleware) RecoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// A handler that has already started its response aborts
+				// it this way; let net/http drop the connection.
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
 				w.Header().Set("Connection", "close")
 				m.customError.ServerErrorResponse(w, r, fmt.Errorf("%v", err))
 			}
@@ -38,50 +45,121 @@ func (m *Middleware) RateLimit(next http.Handler) http.Handler {
 		return next
 	}
 
-	var (
-		mu      sync.Mutex
-		clients = make(map[string]*client)
-	)
-
-	go func() {
-		for {
-			time.Sleep(time.Minute)
+	limiter := helper.NewRateLimiter(rate.Limit(m.config.RateLimiter.RPS), m.config.RateLimiter.Burst, 3*time.Minute)
 
-			mu.Lock()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := realip.FromRequest(r)
 
-			for ip, c := range clients {
-				if time.Since(c.lastSeen) > 3*time.Minute {
-					delete(clients, ip)
-				}
-			}
-			mu.Unlock()
+		if !limiter.Allow(ip) {
+			m.customError.RateLimitExceededResponse(w, r)
+			return
 		}
-	}()
+		next.ServeHTTP(w, r)
+	})
+}
 
+func (m *Middleware) ReadYourWrites(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := realip.FromRequest(r)
-		mu.Lock()
-		if _, found := clients[ip]; !found {
-			clients[ip] = &client{
-				limiter: rate.NewLimiter(rate.Limit(m.config.RateLimiter.RPS), m.config.RateLimiter.Burst),
-			}
+		next.ServeHTTP(w, r.WithContext(repository.WithSession(r.Context())))
+	})
+}
+
+func (m *Middleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		authorizationHeader := r.Header.Get("Authorization")
+		if authorizationHeader == "" {
+			r = helper.ContextSetUser(r, domain.AnonymousUser)
+			next.ServeHTTP(w, r)
+			return
 		}
 
-		clients[ip].lastSeen = time.Now()
+		headerParts := strings.Split(authorizationHeader, " ")
+		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+			m.customError.InvalidAuthenticationTokenResponse(w, r)
+			return
+		}
 
-		if !clients[ip].limiter.Allow() {
-			mu.Unlock()
-			m.customError.RateLimitExceededResponse(w, r)
+		token := headerParts[1]
+
+		v := validator.NewValidator()
+		dto.ValidateTokenPlaintext(v, &dto.ActivateUser{TokenPlaintext: token})
+		if !v.Valid() {
+			m.customError.InvalidAuthenticationTokenResponse(w, r)
 			return
 		}
-		mu.Unlock()
+
+		user, err := m.tokenRepository.GetForToken(r.Context(), domain.ScopeAuthentication, token)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrRecordNotFound):
+				m.customError.InvalidAuthenticationTokenResponse(w, r)
+			default:
+				m.customError.ServerErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		r = helper.ContextSetUser(r, user)
 		next.ServeHTTP(w, r)
 	})
 }
 
-func NewMiddleware(config *config.Config, customError *helper.CustomError) *Middleware {
+func (m *Middleware) RequireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := helper.ContextGetUser(r)
+
+		if user.IsAnonymous() {
+			m.customError.AuthenticationRequiredResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+func (m *Middleware) RequireActivatedUser(next http.HandlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		user := helper.ContextGetUser(r)
+
+		if !user.Activated {
+			m.customError.InactiveAccountResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return m.RequireAuthenticatedUser(fn)
+}
+
+func (m *Middleware) RequirePermission(code string, next http.HandlerFunc) http.HandlerFunc {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		user := helper.ContextGetUser(r)
+
+		permissions, err := m.permissionRepository.GetAllForUser(r.Context(), user.Id)
+		if err != nil {
+			m.customError.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		if !permissions.Include(code) {
+			m.customError.NotPermittedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return m.RequireActivatedUser(fn)
+}
+
+func NewMiddleware(config *config.Config, customError *helper.CustomError, tokenRepository repository.TokenRepository, permissionRepository repository.PermissionRepository) *Middleware {
 	return &Middleware{
-		config:      config,
-		customError: customError,
+		config:               config,
+		customError:          customError,
+		tokenRepository:      tokenRepository,
+		permissionRepository: permissionRepository,
 	}
 }
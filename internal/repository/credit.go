@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"time"
+)
+
+type CreditRepository interface {
+	CreateCredit(ctx context.Context, credit *domain.Credit) error
+	GetCreditsForMovie(ctx context.Context, movieId int64) ([]*domain.Credit, error)
+	GetCreditsForPerson(ctx context.Context, personId int64) ([]*domain.Credit, error)
+	DeleteCredit(ctx context.Context, movieId, creditId int64) error
+	WithTx(tx *sql.Tx) CreditRepository
+}
+
+type creditRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (c *creditRepository) CreateCredit(ctx context.Context, credit *domain.Credit) error {
+	query := `
+        INSERT INTO credits (movie_id, person_id, role, character, billing_order)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`
+
+	args := []any{credit.MovieId, credit.PersonId, credit.Role, credit.Character, credit.BillingOrder}
+
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	if err := c.dbWrite.QueryRowContext(ctx, query, args...).Scan(&credit.Id); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "credits_unique"`:
+			return ErrDuplicateCredit
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (c *creditRepository) GetCreditsForMovie(ctx context.Context, movieId int64) ([]*domain.Credit, error) {
+	query := `
+        SELECT credits.id, credits.movie_id, credits.person_id, credits.role, credits.character, credits.billing_order, people.name
+        FROM credits
+        INNER JOIN people ON people.id = credits.person_id
+        WHERE credits.movie_id = $1
+        ORDER BY credits.role ASC, credits.billing_order ASC, credits.id ASC`
+
+	return c.getCredits(ctx, query, movieId, func(credit *domain.Credit) []any {
+		return []any{&credit.PersonName}
+	})
+}
+
+func (c *creditRepository) GetCreditsForPerson(ctx context.Context, personId int64) ([]*domain.Credit, error) {
+	query := `
+        SELECT credits.id, credits.movie_id, credits.person_id, credits.role, credits.character, credits.billing_order, movies.title, movies.year
+        FROM credits
+        INNER JOIN movies ON movies.id = credits.movie_id
+        WHERE credits.person_id = $1
+        ORDER BY movies.year DESC, movies.id DESC, credits.role ASC`
+
+	return c.getCredits(ctx, query, personId, func(credit *domain.Credit) []any {
+		return []any{&credit.MovieTitle, &credit.MovieYear}
+	})
+}
+
+// getCredits runs a credits listing; extra returns the scan destinations for
+// the joined columns that follow the credit's own.
+func (c *creditRepository) getCredits(ctx context.Context, query string, id int64, extra func(*domain.Credit) []any) ([]*domain.Credit, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	rows, err := c.dbRead.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	credits := []*domain.Credit{}
+	for rows.Next() {
+		var credit domain.Credit
+		dest := []any{&credit.Id, &credit.MovieId, &credit.PersonId, &credit.Role, &credit.Character, &credit.BillingOrder}
+		if err = rows.Scan(append(dest, extra(&credit)...)...); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		credits = append(credits, &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return credits, nil
+}
+
+func (c *creditRepository) DeleteCredit(ctx context.Context, movieId, creditId int64) error {
+	query := `DELETE FROM credits WHERE id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	result, err := c.dbWrite.ExecContext(ctx, query, creditId, movieId)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (c *creditRepository) WithTx(tx *sql.Tx) CreditRepository {
+	return &creditRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: c.queryTimeout,
+	}
+}
+
+func NewCreditRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) CreditRepository {
+	return &creditRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
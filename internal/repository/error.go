@@ -1,9 +1,33 @@
 package repository
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrRecordNotFound = errors.New("record not found")
-	ErrEditConflict   = errors.New("edit conflict")
-	ErrDuplicateEmail = errors.New("duplicate email")
+	ErrRecordNotFound    = errors.New("record not found")
+	ErrEditConflict      = errors.New("edit conflict")
+	ErrDuplicateEmail    = errors.New("duplicate email")
+	ErrDuplicateGenre    = errors.New("duplicate genre")
+	ErrGenreInUse        = errors.New("genre in use")
+	ErrDuplicateCredit   = errors.New("duplicate credit")
+	ErrDuplicateReview   = errors.New("duplicate review")
+	ErrDuplicateList     = errors.New("duplicate list")
+	ErrDuplicateListItem = errors.New("duplicate list item")
+	ErrDuplicateImdbId   = errors.New("duplicate imdb id")
+	ErrDuplicateTmdbId   = errors.New("duplicate tmdb id")
 )
+
+// contextError makes a query that was cut short by its context report the
+// context's error as well, so callers can tell a timeout from a failed query.
+func contextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	return err
+}
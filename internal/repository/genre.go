@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"slices"
+	"time"
+)
+
+type GenreRepository interface {
+	CreateGenre(ctx context.Context, genre *domain.Genre) error
+	GetGenreById(ctx context.Context, id int64) (*domain.Genre, error)
+	GetGenres(ctx context.Context) ([]*domain.Genre, error)
+	UpdateGenre(ctx context.Context, genre *domain.Genre) (*domain.Genre, error)
+	DeleteGenre(ctx context.Context, id int64) error
+	LockGenres(ctx context.Context, ids ...int64) error
+	LockCatalogue(ctx context.Context) error
+	RenameOnMovies(ctx context.Context, oldSlug, newSlug string) error
+	InUse(ctx context.Context, slug string) (bool, error)
+	WithTx(tx *sql.Tx) GenreRepository
+}
+
+type genreRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (g *genreRepository) CreateGenre(ctx context.Context, genre *domain.Genre) error {
+	query := `INSERT INTO genres (slug, name, aliases) VALUES ($1, $2, $3) RETURNING id, created_at, version`
+	args := []any{genre.Slug, genre.Name, pq.Array(genre.Aliases)}
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	if err := g.dbWrite.QueryRowContext(ctx, query, args...).Scan(&genre.Id, &genre.CreatedAt, &genre.Version); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "genres_slug_key"`:
+			return ErrDuplicateGenre
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (g *genreRepository) GetGenreById(ctx context.Context, id int64) (*domain.Genre, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT id, created_at, slug, name, aliases, version FROM genres WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	var genre domain.Genre
+	if err := g.dbRead.QueryRowContext(ctx, query, id).Scan(&genre.Id, &genre.CreatedAt, &genre.Slug, &genre.Name, pq.Array(&genre.Aliases), &genre.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return &genre, nil
+}
+
+func (g *genreRepository) GetGenres(ctx context.Context) ([]*domain.Genre, error) {
+	query := `SELECT id, created_at, slug, name, aliases, version FROM genres ORDER BY slug ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	rows, err := g.dbRead.QueryContext(ctx, query)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	genres := []*domain.Genre{}
+	for rows.Next() {
+		var genre domain.Genre
+		if err = rows.Scan(&genre.Id, &genre.CreatedAt, &genre.Slug, &genre.Name, pq.Array(&genre.Aliases), &genre.Version); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		genres = append(genres, &genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return genres, nil
+}
+
+func (g *genreRepository) UpdateGenre(ctx context.Context, genre *domain.Genre) (*domain.Genre, error) {
+	query := `
+        UPDATE genres
+        SET slug = $1, name = $2, aliases = $3, version = version + 1
+        WHERE id = $4 AND version = $5
+        RETURNING version`
+
+	args := []any{genre.Slug, genre.Name, pq.Array(genre.Aliases), genre.Id, genre.Version}
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	if err := g.dbWrite.QueryRowContext(ctx, query, args...).Scan(&genre.Version); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "genres_slug_key"`:
+			return nil, ErrDuplicateGenre
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+
+	return genre, nil
+}
+
+func (g *genreRepository) DeleteGenre(ctx context.Context, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM genres WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	result, err := g.dbWrite.ExecContext(ctx, query, id)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// LockGenres locks the genres until the transaction ends, in id order so two
+// transactions locking the same genres can't deadlock. It returns
+// ErrRecordNotFound unless every genre exists.
+func (g *genreRepository) LockGenres(ctx context.Context, ids ...int64) error {
+	query := `SELECT id FROM genres WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	rows, err := g.dbWrite.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+
+	if err = rows.Err(); err != nil {
+		return contextError(ctx, err)
+	}
+
+	if locked != len(slices.Compact(slices.Sorted(slices.Values(ids)))) {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// LockCatalogue keeps other transactions from writing to genres until the
+// transaction ends, while still letting them read. Writers that check the
+// catalogue for ambiguous names take it first, so two of them can't both
+// pass the check.
+func (g *genreRepository) LockCatalogue(ctx context.Context) error {
+	query := `LOCK TABLE genres IN SHARE ROW EXCLUSIVE MODE`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	_, err := g.dbWrite.ExecContext(ctx, query)
+	return contextError(ctx, err)
+}
+
+// RenameOnMovies rewrites a genre slug on every movie that carries it, so a
+// renamed genre doesn't leave movies pointing at a slug that no longer exists.
+// A movie that already carries newSlug (after a merge) keeps it only once.
+func (g *genreRepository) RenameOnMovies(ctx context.Context, oldSlug, newSlug string) error {
+	query := `
+        UPDATE movies
+        SET genres = ARRAY(
+                SELECT genre
+                FROM unnest(array_replace(genres, $1, $2)) WITH ORDINALITY AS g(genre, ord)
+                GROUP BY genre
+                ORDER BY min(ord)
+            ),
+            version = version + 1
+        WHERE genres @> ARRAY[$1]`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	_, err := g.dbWrite.ExecContext(ctx, query, oldSlug, newSlug)
+	return contextError(ctx, err)
+}
+
+func (g *genreRepository) InUse(ctx context.Context, slug string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM movies WHERE genres @> ARRAY[$1])`
+
+	ctx, cancel := context.WithTimeout(ctx, g.queryTimeout)
+	defer cancel()
+
+	var inUse bool
+	if err := g.dbRead.QueryRowContext(ctx, query, slug).Scan(&inUse); err != nil {
+		return false, contextError(ctx, err)
+	}
+	return inUse, nil
+}
+
+func (g *genreRepository) WithTx(tx *sql.Tx) GenreRepository {
+	return &genreRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: g.queryTimeout,
+	}
+}
+
+func NewGenreRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) GenreRepository {
+	return &genreRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
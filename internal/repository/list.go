@@ -0,0 +1,539 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"slices"
+	"time"
+)
+
+// ListRepository keeps a user's lists, and the movies on each list, in a
+// gapless 1..n position order. Writes that move positions around expect the
+// caller to hold LockLists or LockList for the rest of the transaction.
+type ListRepository interface {
+	CreateDefaultLists(ctx context.Context, userId int64) error
+	CreateList(ctx context.Context, list *domain.List) error
+	GetListById(ctx context.Context, id int64) (*domain.List, error)
+	GetListsForUser(ctx context.Context, userId int64) ([]*domain.List, error)
+	UpdateList(ctx context.Context, list *domain.List) (*domain.List, error)
+	MoveList(ctx context.Context, list *domain.List, position int32) error
+	DeleteList(ctx context.Context, list *domain.List) error
+	LockLists(ctx context.Context, userId int64) error
+	LockList(ctx context.Context, id int64) error
+	AddItem(ctx context.Context, listId int64, item *domain.ListItem) error
+	GetItem(ctx context.Context, listId, movieId int64) (*domain.ListItem, error)
+	GetItems(ctx context.Context, queryString *dto.QueryListItems) ([]*domain.ListItem, dto.Metadata, error)
+	MoveItem(ctx context.Context, listId, movieId int64, position int32) error
+	SetWatchedAt(ctx context.Context, listId, movieId int64, watchedAt time.Time) error
+	RemoveItem(ctx context.Context, listId, movieId int64) error
+	RemoveMovieFromLists(ctx context.Context, movieId int64) error
+	WithTx(tx *sql.Tx) ListRepository
+}
+
+type listRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (l *listRepository) CreateDefaultLists(ctx context.Context, userId int64) error {
+	query := `
+        INSERT INTO lists (user_id, kind, name, position)
+        VALUES ($1, $2, 'Watchlist', 1), ($1, $3, 'Watched', 2)`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	_, err := l.dbWrite.ExecContext(ctx, query, userId, domain.ListWatchlist, domain.ListWatched)
+	return contextError(ctx, err)
+}
+
+func (l *listRepository) CreateList(ctx context.Context, list *domain.List) error {
+	query := `
+        INSERT INTO lists (user_id, kind, name, public, position)
+        VALUES ($1, $2, $3, $4, (SELECT coalesce(max(position), 0) + 1 FROM lists WHERE user_id = $1))
+        RETURNING id, created_at, position, version`
+
+	args := []any{list.UserId, list.Kind, list.Name, list.Public}
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	if err := l.dbWrite.QueryRowContext(ctx, query, args...).Scan(&list.Id, &list.CreatedAt, &list.Position, &list.Version); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "lists_user_name_key"`:
+			return ErrDuplicateList
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (l *listRepository) GetListById(ctx context.Context, id int64) (*domain.List, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT id, created_at, user_id, kind, name, public, position, version FROM lists WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	var list domain.List
+	if err := l.dbRead.QueryRowContext(ctx, query, id).Scan(&list.Id, &list.CreatedAt, &list.UserId, &list.Kind, &list.Name, &list.Public, &list.Position, &list.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return &list, nil
+}
+
+func (l *listRepository) GetListsForUser(ctx context.Context, userId int64) ([]*domain.List, error) {
+	query := `
+        SELECT id, created_at, user_id, kind, name, public, position, version
+        FROM lists
+        WHERE user_id = $1
+        ORDER BY position ASC, id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	rows, err := l.dbRead.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	lists := []*domain.List{}
+	for rows.Next() {
+		var list domain.List
+		if err = rows.Scan(&list.Id, &list.CreatedAt, &list.UserId, &list.Kind, &list.Name, &list.Public, &list.Position, &list.Version); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		lists = append(lists, &list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return lists, nil
+}
+
+func (l *listRepository) UpdateList(ctx context.Context, list *domain.List) (*domain.List, error) {
+	query := `
+        UPDATE lists
+        SET name = $1, public = $2, version = version + 1
+        WHERE id = $3 AND version = $4
+        RETURNING version`
+
+	args := []any{list.Name, list.Public, list.Id, list.Version}
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	if err := l.dbWrite.QueryRowContext(ctx, query, args...).Scan(&list.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "lists_user_name_key"`:
+			return nil, ErrDuplicateList
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+
+	return list, nil
+}
+
+func (l *listRepository) MoveList(ctx context.Context, list *domain.List, position int32) error {
+	return l.reposition(ctx, "lists", "user_id", list.UserId, "id", list.Id, position)
+}
+
+func (l *listRepository) DeleteList(ctx context.Context, list *domain.List) error {
+	query := `DELETE FROM lists WHERE id = $1 RETURNING position`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	var position int32
+	if err := l.dbWrite.QueryRowContext(ctx, query, list.Id).Scan(&position); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return contextError(ctx, err)
+		}
+	}
+
+	query = `UPDATE lists SET position = position - 1 WHERE user_id = $1 AND position > $2`
+	_, err := l.dbWrite.ExecContext(ctx, query, list.UserId, position)
+	return contextError(ctx, err)
+}
+
+func (l *listRepository) LockLists(ctx context.Context, userId int64) error {
+	query := `SELECT id FROM lists WHERE user_id = $1 FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	_, err := l.dbWrite.ExecContext(ctx, query, userId)
+	return contextError(ctx, err)
+}
+
+func (l *listRepository) LockList(ctx context.Context, id int64) error {
+	query := `SELECT id FROM lists WHERE id = $1 FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	if err := l.dbWrite.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+// AddItem inserts the movie at item.Position, or at the end when that is
+// zero or past the end, and shifts the items from there on down by one.
+func (l *listRepository) AddItem(ctx context.Context, listId int64, item *domain.ListItem) error {
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	var total int32
+	if err := l.dbWrite.QueryRowContext(ctx, `SELECT count(*) FROM list_items WHERE list_id = $1`, listId).Scan(&total); err != nil {
+		return contextError(ctx, err)
+	}
+
+	if item.Position < 1 || item.Position > total+1 {
+		item.Position = total + 1
+	}
+
+	query := `UPDATE list_items SET position = position + 1 WHERE list_id = $1 AND position >= $2`
+	if _, err := l.dbWrite.ExecContext(ctx, query, listId, item.Position); err != nil {
+		return contextError(ctx, err)
+	}
+
+	query = `
+        INSERT INTO list_items (list_id, movie_id, position, watched_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING added_at`
+
+	args := []any{listId, item.Movie.Id, item.Position, item.WatchedAt}
+
+	if err := l.dbWrite.QueryRowContext(ctx, query, args...).Scan(&item.AddedAt); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "list_items_pkey"`:
+			return ErrDuplicateListItem
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+const listItemColumns = `
+        list_items.position, list_items.added_at, list_items.watched_at,
+        movies.id, movies.title, movies.year, movies.runtime, movies.genres, movies.rating, movies.rating_count, movies.poster, movies.backdrop,
+        coalesce(movies.imdb_id, ''), coalesce(movies.tmdb_id, 0), movies.version`
+
+func scanListItem(scan func(dest ...any) error, extra ...any) (*domain.ListItem, error) {
+	item := domain.ListItem{Movie: &domain.Movie{}}
+	dest := append(extra,
+		&item.Position,
+		&item.AddedAt,
+		&item.WatchedAt,
+		&item.Movie.Id,
+		&item.Movie.Title,
+		&item.Movie.Year,
+		&item.Movie.Runtime,
+		pq.Array(&item.Movie.Genres),
+		&item.Movie.Rating,
+		&item.Movie.RatingCount,
+		&item.Movie.Poster,
+		&item.Movie.Backdrop,
+		&item.Movie.ImdbId,
+		&item.Movie.TmdbId,
+		&item.Movie.Version,
+	)
+	if err := scan(dest...); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (l *listRepository) GetItem(ctx context.Context, listId, movieId int64) (*domain.ListItem, error) {
+	query := `
+        SELECT` + listItemColumns + `
+        FROM list_items
+        INNER JOIN movies ON movies.id = list_items.movie_id
+        WHERE list_items.list_id = $1 AND list_items.movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	item, err := scanListItem(l.dbRead.QueryRowContext(ctx, query, listId, movieId).Scan)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return item, nil
+}
+
+// GetItems pages through a list in position order, by page number or, like
+// the movie listing, by a keyset cursor on (position, movie id).
+func (l *listRepository) GetItems(ctx context.Context, queryString *dto.QueryListItems) ([]*domain.ListItem, dto.Metadata, error) {
+	filters := queryString.Filters
+	if !filters.UseCursor {
+		return l.getItemsByPage(ctx, queryString)
+	}
+
+	before := filters.Cursor != nil && filters.Cursor.Before
+	direction, operator := "ASC", ">"
+	if before {
+		direction, operator = "DESC", "<"
+	}
+
+	where := "WHERE list_items.list_id = $1"
+	args := []any{queryString.ListId}
+	if filters.Cursor != nil {
+		where += fmt.Sprintf(" AND (list_items.position, list_items.movie_id) %s ($2, $3)", operator)
+		args = append(args, filters.Cursor.Value, filters.Cursor.Id)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT`+listItemColumns+`
+        FROM list_items
+        INNER JOIN movies ON movies.id = list_items.movie_id
+        %s
+        ORDER BY list_items.position %s, list_items.movie_id %s
+        LIMIT $%d`, where, direction, direction, len(args)+1)
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	rows, err := l.dbRead.QueryContext(ctx, query, append(args, filters.Limit()+1)...)
+	if err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	items := []*domain.ListItem{}
+	for rows.Next() {
+		item, err := scanListItem(rows.Scan)
+		if err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+
+	hasMore := len(items) > filters.Limit()
+	if hasMore {
+		items = items[:filters.Limit()]
+	}
+
+	if before {
+		slices.Reverse(items)
+	}
+
+	metadata := dto.Metadata{
+		PageSize: filters.PageSize,
+		HasNext:  hasMore,
+		HasPrev:  filters.Cursor != nil,
+	}
+	if before {
+		metadata.HasNext, metadata.HasPrev = true, hasMore
+	}
+
+	if filters.IncludeTotal {
+		query := `SELECT count(*) FROM list_items WHERE list_id = $1`
+		if err := l.dbRead.QueryRowContext(ctx, query, queryString.ListId).Scan(&metadata.TotalRecords); err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+	}
+
+	return items, metadata, nil
+}
+
+func (l *listRepository) getItemsByPage(ctx context.Context, queryString *dto.QueryListItems) ([]*domain.ListItem, dto.Metadata, error) {
+	query := `
+        SELECT count(*) OVER(),` + listItemColumns + `
+        FROM list_items
+        INNER JOIN movies ON movies.id = list_items.movie_id
+        WHERE list_items.list_id = $1
+        ORDER BY list_items.position ASC, list_items.movie_id ASC
+        LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	args := []any{queryString.ListId, queryString.Filters.Limit(), queryString.Filters.Offset()}
+
+	rows, err := l.dbRead.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	items := []*domain.ListItem{}
+	for rows.Next() {
+		item, err := scanListItem(rows.Scan, &totalRecords)
+		if err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+
+	metadata := dto.CalculateMetadata(totalRecords, queryString.Filters.Page, queryString.Filters.PageSize)
+
+	return items, metadata, nil
+}
+
+func (l *listRepository) MoveItem(ctx context.Context, listId, movieId int64, position int32) error {
+	return l.reposition(ctx, "list_items", "list_id", listId, "movie_id", movieId, position)
+}
+
+func (l *listRepository) SetWatchedAt(ctx context.Context, listId, movieId int64, watchedAt time.Time) error {
+	query := `UPDATE list_items SET watched_at = $1 WHERE list_id = $2 AND movie_id = $3`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	result, err := l.dbWrite.ExecContext(ctx, query, watchedAt, listId, movieId)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listRepository) RemoveItem(ctx context.Context, listId, movieId int64) error {
+	query := `DELETE FROM list_items WHERE list_id = $1 AND movie_id = $2 RETURNING position`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	var position int32
+	if err := l.dbWrite.QueryRowContext(ctx, query, listId, movieId).Scan(&position); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return contextError(ctx, err)
+		}
+	}
+
+	query = `UPDATE list_items SET position = position - 1 WHERE list_id = $1 AND position > $2`
+	_, err := l.dbWrite.ExecContext(ctx, query, listId, position)
+	return contextError(ctx, err)
+}
+
+// RemoveMovieFromLists takes a movie off every list it is on and closes the
+// gap it leaves behind in each of them.
+func (l *listRepository) RemoveMovieFromLists(ctx context.Context, movieId int64) error {
+	query := `
+        WITH removed AS (
+            DELETE FROM list_items WHERE movie_id = $1 RETURNING list_id, position
+        )
+        UPDATE list_items
+        SET position = list_items.position - 1
+        FROM removed
+        WHERE list_items.list_id = removed.list_id AND list_items.position > removed.position`
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	_, err := l.dbWrite.ExecContext(ctx, query, movieId)
+	return contextError(ctx, err)
+}
+
+// reposition moves one row of an ordered set to position, clamped to the
+// size of the set, and shifts the rows it passes over by one in the other
+// direction. table, scope and key are always constants from this file.
+func (l *listRepository) reposition(ctx context.Context, table, scope string, scopeId int64, key string, keyId int64, position int32) error {
+	query := fmt.Sprintf(`
+        WITH target AS (
+            SELECT position AS old,
+                   least(greatest($3::integer, 1), (SELECT count(*) FROM %[1]s WHERE %[2]s = $1))::integer AS new
+            FROM %[1]s
+            WHERE %[2]s = $1 AND %[3]s = $2
+        )
+        UPDATE %[1]s
+        SET position = CASE
+            WHEN %[3]s = $2 THEN target.new
+            WHEN target.new < target.old THEN position + 1
+            ELSE position - 1
+        END
+        FROM target
+        WHERE %[2]s = $1 AND position BETWEEN least(target.old, target.new) AND greatest(target.old, target.new)`, table, scope, key)
+
+	ctx, cancel := context.WithTimeout(ctx, l.queryTimeout)
+	defer cancel()
+
+	result, err := l.dbWrite.ExecContext(ctx, query, scopeId, keyId, position)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listRepository) WithTx(tx *sql.Tx) ListRepository {
+	return &listRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: l.queryTimeout,
+	}
+}
+
+func NewListRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) ListRepository {
+	return &listRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
@@ -3,33 +3,153 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/lib/pq"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"slices"
+	"strings"
 	"time"
 )
 
 type MovieRepository interface {
 	CreateMovie(ctx context.Context, movie *domain.Movie) error
+	CreateMovies(ctx context.Context, movies []*domain.Movie) error
+	GetTakenExternalIds(ctx context.Context, imdbIds []string, tmdbIds []int64) ([]string, []int64, error)
 	GetMovieById(ctx context.Context, id int64) (*domain.Movie, error)
+	GetMovieByTmdbId(ctx context.Context, tmdbId int64) (*domain.Movie, error)
 	GetMovies(ctx context.Context, queryString *dto.QueryMovie) ([]*domain.Movie, dto.Metadata, error)
+	SuggestMovies(ctx context.Context, input *dto.SuggestMovie) ([]*domain.MovieSuggestion, error)
+	GetSimilarMovies(ctx context.Context, id int64, limit int) ([]*domain.SimilarMovie, error)
+	GetMovieFacets(ctx context.Context, queryString *dto.QueryMovie) (domain.Facets, error)
+	ExportMovies(ctx context.Context, queryString *dto.QueryMovie, fn func(*domain.Movie) error) error
 	UpdateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error)
-	DeleteMovie(ctx context.Context, id int64) error
+	DeleteMovie(ctx context.Context, id int64, version int32) error
+	LockMovie(ctx context.Context, id int64) error
+	RefreshRating(ctx context.Context, id int64) error
+	SetArtwork(ctx context.Context, id int64, kind string, artwork domain.Artwork) error
+	WithTx(tx *sql.Tx) MovieRepository
 }
 
 type movieRepository struct {
-	dbWrite *sql.DB
-	dbRead  *sql.DB
+	dbWrite        DBTX
+	dbRead         DBTX
+	queryTimeout   time.Duration
+	suggestTimeout time.Duration
+	exportTimeout  time.Duration
 }
 
 func (m *movieRepository) CreateMovie(ctx context.Context, movie *domain.Movie) error {
-	query := `INSERT INTO movies(title, year, runtime, genres) VALUES ($1, $2, $3, $4) RETURNING id, created_at, version`
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	query := `
+        INSERT INTO movies(title, year, runtime, genres, imdb_id, tmdb_id)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6::bigint, 0))
+        RETURNING id, created_at, version`
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.ImdbId, movie.TmdbId}
+	if err := m.dbWrite.QueryRowContext(ctx, query, args...).Scan(&movie.Id, &movie.CreatedAt, &movie.Version); err != nil {
+		return externalIdError(ctx, err)
+	}
+	return nil
+}
+
+// CreateMovies inserts movies with a single multi-row INSERT and fills in
+// their ids. RETURNING hands the rows back in the order of the VALUES list.
+func (m *movieRepository) CreateMovies(ctx context.Context, movies []*domain.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(movies))
+	args := make([]any, 0, len(movies)*6)
+	for _, movie := range movies {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, NULLIF($%d, ''), NULLIF($%d::bigint, 0))", n+1, n+2, n+3, n+4, n+5, n+6))
+		args = append(args, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.ImdbId, movie.TmdbId)
+	}
+
+	query := `
+        INSERT INTO movies(title, year, runtime, genres, imdb_id, tmdb_id)
+        VALUES ` + strings.Join(values, ", ") + `
+        RETURNING id, created_at, version`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
 	defer cancel()
-	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres)}
-	return m.dbWrite.QueryRowContext(ctx, query, args...).Scan(&movie.Id, &movie.CreatedAt, &movie.Version)
+
+	rows, err := m.dbWrite.QueryContext(ctx, query, args...)
+	if err != nil {
+		return externalIdError(ctx, err)
+	}
+	defer rows.Close()
+
+	i := 0
+	for rows.Next() {
+		if err = rows.Scan(&movies[i].Id, &movies[i].CreatedAt, &movies[i].Version); err != nil {
+			return contextError(ctx, err)
+		}
+		i++
+	}
+
+	if err = rows.Err(); err != nil {
+		return externalIdError(ctx, err)
+	}
+
+	return nil
+}
+
+// GetTakenExternalIds returns which of the given IMDb and TMDB ids are
+// already used by a movie.
+func (m *movieRepository) GetTakenExternalIds(ctx context.Context, imdbIds []string, tmdbIds []int64) ([]string, []int64, error) {
+	query := `
+        SELECT coalesce(imdb_id, ''), coalesce(tmdb_id, 0)
+        FROM movies
+        WHERE imdb_id = ANY($1) OR tmdb_id = ANY($2)`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	rows, err := m.dbWrite.QueryContext(ctx, query, pq.Array(imdbIds), pq.Array(tmdbIds))
+	if err != nil {
+		return nil, nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	var takenImdb []string
+	var takenTmdb []int64
+	for rows.Next() {
+		var imdbId string
+		var tmdbId int64
+		if err = rows.Scan(&imdbId, &tmdbId); err != nil {
+			return nil, nil, contextError(ctx, err)
+		}
+		if imdbId != "" && slices.Contains(imdbIds, imdbId) {
+			takenImdb = append(takenImdb, imdbId)
+		}
+		if tmdbId != 0 && slices.Contains(tmdbIds, tmdbId) {
+			takenTmdb = append(takenTmdb, tmdbId)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, contextError(ctx, err)
+	}
+
+	return takenImdb, takenTmdb, nil
+}
+
+// externalIdError reports a clash on the unique imdb_id or tmdb_id columns
+// as the matching Err*, and any other error as it is.
+func externalIdError(ctx context.Context, err error) error {
+	switch {
+	case err.Error() == `pq: duplicate key value violates unique constraint "movies_imdb_id_key"`:
+		return ErrDuplicateImdbId
+	case err.Error() == `pq: duplicate key value violates unique constraint "movies_tmdb_id_key"`:
+		return ErrDuplicateTmdbId
+	default:
+		return contextError(ctx, err)
+	}
 }
 
 func (m *movieRepository) GetMovieById(ctx context.Context, id int64) (*domain.Movie, error) {
@@ -39,39 +159,166 @@ func (m *movieRepository) GetMovieById(ctx context.Context, id int64) (*domain.M
 	}
 
 	query := `
-        SELECT id, created_at, title, year, runtime, genres, version FROM movies WHERE id = $1`
+        SELECT id, created_at, title, year, runtime, genres, rating, rating_count, poster, backdrop, coalesce(imdb_id, ''), coalesce(tmdb_id, 0), version FROM movies WHERE id = $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
 	defer cancel()
 
-	if err := m.dbRead.QueryRowContext(ctx, query, id).Scan(&movie.Id, &movie.CreatedAt, &movie.Title, &movie.Year, &movie.Runtime, pq.Array(&movie.Genres), &movie.Version); err != nil {
+	if err := m.dbRead.QueryRowContext(ctx, query, id).Scan(&movie.Id, &movie.CreatedAt, &movie.Title, &movie.Year, &movie.Runtime, pq.Array(&movie.Genres), &movie.Rating, &movie.RatingCount, &movie.Poster, &movie.Backdrop, &movie.ImdbId, &movie.TmdbId, &movie.Version); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, contextError(ctx, err)
 		}
 	}
 	return &movie, nil
 }
 
+func (m *movieRepository) GetMovieByTmdbId(ctx context.Context, tmdbId int64) (*domain.Movie, error) {
+	var movie domain.Movie
+	if tmdbId < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+        SELECT id, created_at, title, year, runtime, genres, rating, rating_count, poster, backdrop, coalesce(imdb_id, ''), tmdb_id, version FROM movies WHERE tmdb_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	if err := m.dbRead.QueryRowContext(ctx, query, tmdbId).Scan(&movie.Id, &movie.CreatedAt, &movie.Title, &movie.Year, &movie.Runtime, pq.Array(&movie.Genres), &movie.Rating, &movie.RatingCount, &movie.Poster, &movie.Backdrop, &movie.ImdbId, &movie.TmdbId, &movie.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return &movie, nil
+}
+
+// movieFilter builds the WHERE clause shared by every query that lists
+// movies, together with its arguments and an expression ranking rows against
+// the title search (empty without one). Callers append their own arguments
+// after the returned ones. With fuzzy set the title is matched by trigram
+// similarity instead of full-text search, which tolerates typos.
+func movieFilter(queryString *dto.QueryMovie, fuzzy bool) (where, rank string, args []any) {
+	var conditions []string
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if queryString.Title != "" {
+		title := arg(queryString.Title)
+		if fuzzy {
+			conditions = append(conditions, fmt.Sprintf("%s <%% title", title))
+			rank = fmt.Sprintf("word_similarity(%s, title)", title)
+		} else {
+			conditions = append(conditions, fmt.Sprintf("to_tsvector('simple', title) @@ websearch_to_tsquery('simple', %s)", title))
+			rank = fmt.Sprintf("ts_rank(to_tsvector('simple', title), websearch_to_tsquery('simple', %s))", title)
+		}
+	}
+
+	if len(queryString.Genres) > 0 {
+		operator := "@>"
+		if queryString.GenresAny {
+			operator = "&&"
+		}
+		conditions = append(conditions, fmt.Sprintf("genres %s %s", operator, arg(pq.Array(queryString.Genres))))
+	}
+
+	if len(queryString.ExcludeGenres) > 0 {
+		conditions = append(conditions, fmt.Sprintf("NOT (genres && %s)", arg(pq.Array(queryString.ExcludeGenres))))
+	}
+
+	if queryString.YearFrom != 0 {
+		conditions = append(conditions, fmt.Sprintf("year >= %s", arg(queryString.YearFrom)))
+	}
+
+	if queryString.YearTo != 0 {
+		conditions = append(conditions, fmt.Sprintf("year <= %s", arg(queryString.YearTo)))
+	}
+
+	if queryString.RuntimeMin != 0 {
+		conditions = append(conditions, fmt.Sprintf("runtime >= %s", arg(queryString.RuntimeMin)))
+	}
+
+	if queryString.RuntimeMax != 0 {
+		conditions = append(conditions, fmt.Sprintf("runtime <= %s", arg(queryString.RuntimeMax)))
+	}
+
+	if queryString.PersonId != 0 {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM credits WHERE credits.movie_id = movies.id AND credits.person_id = %s)", arg(queryString.PersonId)))
+	}
+
+	if len(conditions) == 0 {
+		return "WHERE true", rank, args
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), rank, args
+}
+
+// GetMovies searches titles with full-text search first. Only when that
+// finds nothing at all is the search repeated with trigram matching, so a
+// misspelled title still turns something up.
 func (m *movieRepository) GetMovies(ctx context.Context, queryString *dto.QueryMovie) ([]*domain.Movie, dto.Metadata, error) {
+	movies, metadata, err := m.getMovies(ctx, queryString, false)
+	if err != nil || len(movies) > 0 || queryString.Title == "" {
+		return movies, metadata, err
+	}
+
+	found, err := m.titleMatches(ctx, queryString)
+	if err != nil || found {
+		return movies, metadata, err
+	}
+
+	return m.getMovies(ctx, queryString, true)
+}
+
+func (m *movieRepository) titleMatches(ctx context.Context, queryString *dto.QueryMovie) (bool, error) {
+	where, _, args := movieFilter(queryString, false)
+	query := `SELECT EXISTS (SELECT 1 FROM movies ` + where + `)`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	var found bool
+	if err := m.dbRead.QueryRowContext(ctx, query, args...).Scan(&found); err != nil {
+		return false, contextError(ctx, err)
+	}
+	return found, nil
+}
+
+func (m *movieRepository) getMovies(ctx context.Context, queryString *dto.QueryMovie, fuzzy bool) ([]*domain.Movie, dto.Metadata, error) {
+	if queryString.Filters.UseCursor {
+		return m.getMoviesByCursor(ctx, queryString, fuzzy)
+	}
+
+	where, rank, args := movieFilter(queryString, fuzzy)
+
+	orderBy := fmt.Sprintf("%s %s", queryString.Filters.SortColumn(), queryString.Filters.SortDirection())
+	if queryString.Filters.SortColumn() == "relevance" {
+		orderBy = rank + " DESC"
+	}
+
 	query := fmt.Sprintf(`
-        SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, version
+        SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, rating, rating_count, poster, backdrop, coalesce(imdb_id, ''), coalesce(tmdb_id, 0), version
         FROM movies
-        WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '') 
-        AND (genres @> $2 OR $2 = '{}')     
-        ORDER BY %s %s, id ASC
-        LIMIT $3 OFFSET $4`, queryString.Filters.SortColumn(), queryString.Filters.SortDirection())
+        %s
+        ORDER BY %s, id ASC
+        LIMIT $%d OFFSET $%d`, where, orderBy, len(args)+1, len(args)+2)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
 	defer cancel()
 
-	args := []any{queryString.Title, pq.Array(queryString.Genres), queryString.Filters.Limit(), queryString.Filters.Offset()}
+	args = append(args, queryString.Filters.Limit(), queryString.Filters.Offset())
 
 	rows, err := m.dbRead.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, dto.Metadata{}, err
+		return nil, dto.Metadata{}, contextError(ctx, err)
 	}
 	defer rows.Close()
 
@@ -87,15 +334,21 @@ func (m *movieRepository) GetMovies(ctx context.Context, queryString *dto.QueryM
 			&movie.Year,
 			&movie.Runtime,
 			pq.Array(&movie.Genres),
+			&movie.Rating,
+			&movie.RatingCount,
+			&movie.Poster,
+			&movie.Backdrop,
+			&movie.ImdbId,
+			&movie.TmdbId,
 			&movie.Version,
 		); err != nil {
-			return nil, dto.Metadata{}, err
+			return nil, dto.Metadata{}, contextError(ctx, err)
 		}
 		movies = append(movies, &movie)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, dto.Metadata{}, err
+		return nil, dto.Metadata{}, contextError(ctx, err)
 	}
 
 	metadata := dto.CalculateMetadata(totalRecords, queryString.Filters.Page, queryString.Filters.PageSize)
@@ -103,16 +356,359 @@ func (m *movieRepository) GetMovies(ctx context.Context, queryString *dto.QueryM
 	return movies, metadata, nil
 }
 
+// getMoviesByCursor pages with a keyset on (sort column, id) instead of an
+// OFFSET, so deep pages cost the same as the first one. One extra row is
+// fetched to learn whether there is another page in the scan direction.
+func (m *movieRepository) getMoviesByCursor(ctx context.Context, queryString *dto.QueryMovie, fuzzy bool) ([]*domain.Movie, dto.Metadata, error) {
+	filters := queryString.Filters
+	column := filters.SortColumn()
+	before := filters.Cursor != nil && filters.Cursor.Before
+
+	// Paging backwards walks the index the other way round and the rows are
+	// flipped back into display order afterwards.
+	descending := (filters.SortDirection() == "DESC") != before
+	direction, operator := "ASC", ">"
+	if descending {
+		direction, operator = "DESC", "<"
+	}
+
+	where, _, args := movieFilter(queryString, fuzzy)
+	if filters.Cursor != nil {
+		where += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", column, operator, len(args)+1, len(args)+2)
+		args = append(args, filters.Cursor.Value, filters.Cursor.Id)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, created_at, title, year, runtime, genres, rating, rating_count, poster, backdrop, coalesce(imdb_id, ''), coalesce(tmdb_id, 0), version
+        FROM movies
+        %s
+        ORDER BY %s %s, id %s
+        LIMIT $%d`, where, column, direction, direction, len(args)+1)
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	rows, err := m.dbRead.QueryContext(ctx, query, append(args, filters.Limit()+1)...)
+	if err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	var movies []*domain.Movie
+	for rows.Next() {
+		var movie domain.Movie
+		if err = rows.Scan(
+			&movie.Id,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Rating,
+			&movie.RatingCount,
+			&movie.Poster,
+			&movie.Backdrop,
+			&movie.ImdbId,
+			&movie.TmdbId,
+			&movie.Version,
+		); err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+
+	hasMore := len(movies) > filters.Limit()
+	if hasMore {
+		movies = movies[:filters.Limit()]
+	}
+
+	if before {
+		slices.Reverse(movies)
+	}
+
+	metadata := dto.Metadata{
+		PageSize: filters.PageSize,
+		HasNext:  hasMore,
+		HasPrev:  filters.Cursor != nil,
+	}
+	if before {
+		metadata.HasNext, metadata.HasPrev = true, hasMore
+	}
+
+	if filters.IncludeTotal {
+		where, _, args := movieFilter(queryString, fuzzy)
+		query := `SELECT count(*) FROM movies ` + where
+		if err := m.dbRead.QueryRowContext(ctx, query, args...).Scan(&metadata.TotalRecords); err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+	}
+
+	return movies, metadata, nil
+}
+
+// ExportMovies hands every movie matching the same filters as GetMovies to
+// fn, in sort order, as the rows arrive, so the result is never held in
+// memory as a whole. Titles are only matched by full-text search. It runs
+// under its own, much longer timeout, and stops at the first error from fn.
+func (m *movieRepository) ExportMovies(ctx context.Context, queryString *dto.QueryMovie, fn func(*domain.Movie) error) error {
+	where, rank, args := movieFilter(queryString, false)
+
+	orderBy := fmt.Sprintf("%s %s", queryString.Filters.SortColumn(), queryString.Filters.SortDirection())
+	if queryString.Filters.SortColumn() == "relevance" {
+		orderBy = rank + " DESC"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, created_at, title, year, runtime, genres, rating, rating_count, poster, backdrop, coalesce(imdb_id, ''), coalesce(tmdb_id, 0), version
+        FROM movies
+        %s
+        ORDER BY %s, id ASC`, where, orderBy)
+
+	ctx, cancel := context.WithTimeout(ctx, m.exportTimeout)
+	defer cancel()
+
+	rows, err := m.dbRead.QueryContext(ctx, query, args...)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie domain.Movie
+		if err = rows.Scan(
+			&movie.Id,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Rating,
+			&movie.RatingCount,
+			&movie.Poster,
+			&movie.Backdrop,
+			&movie.ImdbId,
+			&movie.TmdbId,
+			&movie.Version,
+		); err != nil {
+			return contextError(ctx, err)
+		}
+
+		if err = fn(&movie); err != nil {
+			return err
+		}
+	}
+
+	return contextError(ctx, rows.Err())
+}
+
+// facetQueries holds, per facet, the bucket expression and the FROM clause it
+// needs; genres are unnested so a movie counts once for each of its genres.
+var facetQueries = map[string]struct {
+	bucket string
+	from   string
+	order  string
+}{
+	"genres": {bucket: "genre", from: "movies CROSS JOIN LATERAL unnest(genres) AS genre", order: "count(*) DESC, genre ASC"},
+	"year":   {bucket: "year::text", from: "movies", order: "year::text DESC"},
+	"decade": {bucket: "(year / 10 * 10)::text", from: "movies", order: "(year / 10 * 10)::text DESC"},
+}
+
+// GetMovieFacets counts the movies matching the same filters as GetMovies in
+// each bucket of the requested facets. Like GetMovies it switches to trigram
+// title matching when full-text search finds nothing.
+func (m *movieRepository) GetMovieFacets(ctx context.Context, queryString *dto.QueryMovie) (domain.Facets, error) {
+	fuzzy := false
+	if queryString.Title != "" {
+		found, err := m.titleMatches(ctx, queryString)
+		if err != nil {
+			return nil, err
+		}
+		fuzzy = !found
+	}
+
+	where, _, args := movieFilter(queryString, fuzzy)
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	facets := make(domain.Facets, len(queryString.Facets))
+	for _, facet := range queryString.Facets {
+		fq, ok := facetQueries[facet]
+		if !ok {
+			panic("unsafe facet parameter: " + facet)
+		}
+
+		query := fmt.Sprintf(`
+        SELECT %s, count(*)
+        FROM %s
+        %s
+        GROUP BY 1
+        ORDER BY %s`, fq.bucket, fq.from, where, fq.order)
+
+		buckets, err := m.facetBuckets(ctx, query, args)
+		if err != nil {
+			return nil, err
+		}
+		facets[facet] = buckets
+	}
+
+	return facets, nil
+}
+
+func (m *movieRepository) facetBuckets(ctx context.Context, query string, args []any) ([]domain.FacetBucket, error) {
+	rows, err := m.dbRead.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	buckets := []domain.FacetBucket{}
+	for rows.Next() {
+		var bucket domain.FacetBucket
+		if err = rows.Scan(&bucket.Value, &bucket.Count); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return buckets, nil
+}
+
+// SuggestMovies is the typeahead lookup. It only matches title prefixes, which
+// the movies_title_prefix_idx index answers without a scan, and runs under
+// its own, tighter timeout.
+func (m *movieRepository) SuggestMovies(ctx context.Context, input *dto.SuggestMovie) ([]*domain.MovieSuggestion, error) {
+	query := `
+        SELECT id, title, year
+        FROM movies
+        WHERE lower(title) LIKE lower($1) || '%' ESCAPE '\'
+        ORDER BY lower(title) ASC, id ASC
+        LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.suggestTimeout)
+	defer cancel()
+
+	prefix := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(input.Query)
+
+	rows, err := m.dbRead.QueryContext(ctx, query, prefix, input.Limit)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	suggestions := []*domain.MovieSuggestion{}
+	for rows.Next() {
+		var suggestion domain.MovieSuggestion
+		if err = rows.Scan(&suggestion.Id, &suggestion.Title, &suggestion.Year); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return suggestions, nil
+}
+
+// GetSimilarMovies ranks the movies sharing a genre with, or rated by the
+// same users as, the given one. The score weighs the Jaccard similarity of
+// the genres (0.5), closeness in year (0.2) and in runtime (0.1), and how
+// closely users who rated both agreed on them (0.2), damped while few users
+// have. Without any co-ratings that last term is simply zero.
+func (m *movieRepository) GetSimilarMovies(ctx context.Context, id int64, limit int) ([]*domain.SimilarMovie, error) {
+	query := `
+        WITH target AS (
+            SELECT id, genres, year, runtime FROM movies WHERE id = $1
+        ),
+        co_rated AS (
+            SELECT theirs.movie_id, count(*) AS raters, avg(1 - abs(mine.rating - theirs.rating) / 9.0) AS agreement
+            FROM reviews mine
+            INNER JOIN reviews theirs ON theirs.user_id = mine.user_id AND theirs.movie_id <> mine.movie_id
+            WHERE mine.movie_id = $1
+            GROUP BY theirs.movie_id
+        )
+        SELECT movies.id, movies.created_at, movies.title, movies.year, movies.runtime, movies.genres,
+               movies.rating, movies.rating_count, movies.poster, movies.backdrop,
+               coalesce(movies.imdb_id, ''), coalesce(movies.tmdb_id, 0), movies.version, scores.score
+        FROM movies
+        CROSS JOIN target
+        LEFT JOIN co_rated ON co_rated.movie_id = movies.id
+        CROSS JOIN LATERAL (
+            SELECT (
+                0.5 * coalesce(
+                    cardinality(ARRAY(SELECT unnest(movies.genres) INTERSECT SELECT unnest(target.genres)))::float8 /
+                    nullif(cardinality(ARRAY(SELECT unnest(movies.genres) UNION SELECT unnest(target.genres))), 0),
+                0)
+                + 0.2 / (1 + abs(movies.year - target.year) / 5.0)
+                + 0.1 * least(movies.runtime, target.runtime)::float8 / greatest(movies.runtime, target.runtime, 1)
+                + 0.2 * coalesce(co_rated.agreement * co_rated.raters / (co_rated.raters + 5.0), 0)
+            )::float8 AS score
+        ) AS scores
+        WHERE movies.id <> target.id AND (movies.genres && target.genres OR co_rated.movie_id IS NOT NULL)
+        ORDER BY scores.score DESC, movies.id ASC
+        LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	rows, err := m.dbRead.QueryContext(ctx, query, id, limit)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	similar := []*domain.SimilarMovie{}
+	for rows.Next() {
+		var movie domain.Movie
+		var score float64
+		if err = rows.Scan(
+			&movie.Id,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Rating,
+			&movie.RatingCount,
+			&movie.Poster,
+			&movie.Backdrop,
+			&movie.ImdbId,
+			&movie.TmdbId,
+			&movie.Version,
+			&score,
+		); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		similar = append(similar, &domain.SimilarMovie{Movie: &movie, Score: score})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return similar, nil
+}
+
 func (m *movieRepository) UpdateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error) {
 	query := `
         UPDATE movies 
-        SET title = $1, year = $2, runtime = $3, genres = $4, version = version + 1
-        WHERE id = $5 AND version = $6
+        SET title = $1, year = $2, runtime = $3, genres = $4, imdb_id = NULLIF($5, ''), tmdb_id = NULLIF($6::bigint, 0), version = version + 1
+        WHERE id = $7 AND version = $8
         RETURNING version`
 
-	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.Id, movie.Version}
+	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.ImdbId, movie.TmdbId, movie.Id, movie.Version}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
 	defer cancel()
 
 	if err := m.dbWrite.QueryRowContext(ctx, query, args...).Scan(&movie.Version); err != nil {
@@ -120,28 +716,75 @@ func (m *movieRepository) UpdateMovie(ctx context.Context, movie *domain.Movie)
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrEditConflict
 		default:
-			return nil, err
+			return nil, externalIdError(ctx, err)
 		}
 	}
 
 	return movie, nil
 }
 
-func (m *movieRepository) DeleteMovie(ctx context.Context, id int64) error {
+// LockMovie takes a row lock on the movie for the rest of the transaction,
+// so concurrent review writes recompute its rating one after another.
+func (m *movieRepository) LockMovie(ctx context.Context, id int64) error {
 	if id < 1 {
 		return ErrRecordNotFound
 	}
 
-	query := `DELETE FROM movies WHERE id = $1`
+	query := `SELECT id FROM movies WHERE id = $1 FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	if err := m.dbWrite.QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+// RefreshRating recomputes the denormalized rating and rating_count from the
+// movie's reviews. The version is left alone: the rating is not an edit of
+// the movie and must not make a concurrent update fail.
+func (m *movieRepository) RefreshRating(ctx context.Context, id int64) error {
+	query := `
+        UPDATE movies
+        SET rating = coalesce(r.average, 0), rating_count = r.total
+        FROM (SELECT round(avg(rating), 2) AS average, count(*) AS total FROM reviews WHERE movie_id = $1) AS r
+        WHERE movies.id = $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
 	defer cancel()
 
-	result, err := m.dbWrite.ExecContext(ctx, query, id)
+	_, err := m.dbWrite.ExecContext(ctx, query, id)
+	return contextError(ctx, err)
+}
+
+// SetArtwork replaces the poster or backdrop URLs of a movie. Like the
+// rating it does not count as an edit, so the version is left alone.
+func (m *movieRepository) SetArtwork(ctx context.Context, id int64, kind string, artwork domain.Artwork) error {
+	if kind != domain.ArtworkPoster && kind != domain.ArtworkBackdrop {
+		return fmt.Errorf("unknown artwork kind %q", kind)
+	}
+
+	js, err := json.Marshal(artwork)
 	if err != nil {
 		return err
 	}
 
+	query := fmt.Sprintf(`UPDATE movies SET %s = $1 WHERE id = $2`, kind)
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	result, err := m.dbWrite.ExecContext(ctx, query, string(js), id)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
@@ -154,9 +797,51 @@ func (m *movieRepository) DeleteMovie(ctx context.Context, id int64) error {
 	return nil
 }
 
-func NewMovieRepository(dbWrite, dbRead *sql.DB) MovieRepository {
+// DeleteMovie deletes the movie only while it is still at version, like
+// UpdateMovie; ErrEditConflict means it has changed or is already gone.
+func (m *movieRepository) DeleteMovie(ctx context.Context, id int64, version int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM movies WHERE id = $1 AND version = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, m.queryTimeout)
+	defer cancel()
+
+	result, err := m.dbWrite.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEditConflict
+	}
+
+	return nil
+}
+
+func (m *movieRepository) WithTx(tx *sql.Tx) MovieRepository {
+	return &movieRepository{
+		dbWrite:        tx,
+		dbRead:         tx,
+		queryTimeout:   m.queryTimeout,
+		suggestTimeout: m.suggestTimeout,
+		exportTimeout:  m.exportTimeout,
+	}
+}
+
+func NewMovieRepository(dbWrite, dbRead DBTX, queryTimeout, suggestTimeout, exportTimeout time.Duration) MovieRepository {
 	return &movieRepository{
-		dbWrite: dbWrite,
-		dbRead:  dbRead,
+		dbWrite:        dbWrite,
+		dbRead:         dbRead,
+		queryTimeout:   queryTimeout,
+		suggestTimeout: suggestTimeout,
+		exportTimeout:  exportTimeout,
 	}
 }
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"time"
+)
+
+type PermissionRepository interface {
+	GetAllForUser(ctx context.Context, userId int64) (domain.Permissions, error)
+	AddForUser(ctx context.Context, userId int64, codes ...string) error
+	WithTx(tx *sql.Tx) PermissionRepository
+}
+
+type permissionRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (p *permissionRepository) GetAllForUser(ctx context.Context, userId int64) (domain.Permissions, error) {
+	query := `
+        SELECT permissions.code
+        FROM permissions
+        INNER JOIN users_permissions ON users_permissions.permission_id = permissions.id
+        INNER JOIN users ON users_permissions.user_id = users.id
+        WHERE users.id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	rows, err := p.dbRead.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	var permissions domain.Permissions
+	for rows.Next() {
+		var permission string
+		if err = rows.Scan(&permission); err != nil {
+			return nil, contextError(ctx, err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return permissions, nil
+}
+
+func (p *permissionRepository) AddForUser(ctx context.Context, userId int64, codes ...string) error {
+	query := `
+        INSERT INTO users_permissions
+        SELECT $1, permissions.id FROM permissions WHERE permissions.code = ANY($2)`
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	_, err := p.dbWrite.ExecContext(ctx, query, userId, pq.Array(codes))
+	return contextError(ctx, err)
+}
+
+func (p *permissionRepository) WithTx(tx *sql.Tx) PermissionRepository {
+	return &permissionRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: p.queryTimeout,
+	}
+}
+
+func NewPermissionRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) PermissionRepository {
+	return &permissionRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"time"
+)
+
+type PersonRepository interface {
+	CreatePerson(ctx context.Context, person *domain.Person) error
+	GetPersonById(ctx context.Context, id int64) (*domain.Person, error)
+	GetPeople(ctx context.Context, queryString *dto.QueryPerson) ([]*domain.Person, dto.Metadata, error)
+	UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error)
+	DeletePerson(ctx context.Context, id int64) error
+	WithTx(tx *sql.Tx) PersonRepository
+}
+
+type personRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (p *personRepository) CreatePerson(ctx context.Context, person *domain.Person) error {
+	query := `INSERT INTO people (name, birth_year, biography) VALUES ($1, NULLIF($2, 0), $3) RETURNING id, created_at, version`
+	args := []any{person.Name, person.BirthYear, person.Biography}
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	return contextError(ctx, p.dbWrite.QueryRowContext(ctx, query, args...).Scan(&person.Id, &person.CreatedAt, &person.Version))
+}
+
+func (p *personRepository) GetPersonById(ctx context.Context, id int64) (*domain.Person, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT id, created_at, name, coalesce(birth_year, 0), biography, version FROM people WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	var person domain.Person
+	if err := p.dbRead.QueryRowContext(ctx, query, id).Scan(&person.Id, &person.CreatedAt, &person.Name, &person.BirthYear, &person.Biography, &person.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return &person, nil
+}
+
+func (p *personRepository) GetPeople(ctx context.Context, queryString *dto.QueryPerson) ([]*domain.Person, dto.Metadata, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, name, coalesce(birth_year, 0), biography, version
+        FROM people
+        WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        ORDER BY %s %s, id ASC
+        LIMIT $2 OFFSET $3`, queryString.Filters.SortColumn(), queryString.Filters.SortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	args := []any{queryString.Name, queryString.Filters.Limit(), queryString.Filters.Offset()}
+
+	rows, err := p.dbRead.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	people := []*domain.Person{}
+	for rows.Next() {
+		var person domain.Person
+		if err = rows.Scan(
+			&totalRecords,
+			&person.Id,
+			&person.CreatedAt,
+			&person.Name,
+			&person.BirthYear,
+			&person.Biography,
+			&person.Version,
+		); err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+		people = append(people, &person)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+
+	metadata := dto.CalculateMetadata(totalRecords, queryString.Filters.Page, queryString.Filters.PageSize)
+
+	return people, metadata, nil
+}
+
+func (p *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	query := `
+        UPDATE people
+        SET name = $1, birth_year = NULLIF($2, 0), biography = $3, version = version + 1
+        WHERE id = $4 AND version = $5
+        RETURNING version`
+
+	args := []any{person.Name, person.BirthYear, person.Biography, person.Id, person.Version}
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	if err := p.dbWrite.QueryRowContext(ctx, query, args...).Scan(&person.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+
+	return person, nil
+}
+
+func (p *personRepository) DeletePerson(ctx context.Context, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM people WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	defer cancel()
+
+	result, err := p.dbWrite.ExecContext(ctx, query, id)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *personRepository) WithTx(tx *sql.Tx) PersonRepository {
+	return &personRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: p.queryTimeout,
+	}
+}
+
+func NewPersonRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) PersonRepository {
+	return &personRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
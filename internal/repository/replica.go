@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
+
+type sessionKey struct{}
+
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession marks ctx as a single unit of work (usually one HTTP request).
+// Once anything in the session writes to the primary, every later read in
+// the same session is sent to the primary too, so callers see their own writes.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// WithPrimaryReads sends every later read in ctx's session to the primary,
+// starting a session if ctx has none. It is for a caller that has to see a
+// row another session has only just committed.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionKey{}).(*session); !ok {
+		ctx = WithSession(ctx)
+	}
+	markWritten(ctx)
+	return ctx
+}
+
+func markWritten(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+func hasWritten(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.wrote.Load()
+}
+
+// Primary is the write side of the pool. It records writes against the
+// session in ctx before handing the query to the primary database.
+type Primary struct {
+	db *sql.DB
+}
+
+func (p *Primary) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	markWritten(ctx)
+	return p.db.ExecContext(ctx, query, args...)
+}
+
+func (p *Primary) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	markWritten(ctx)
+	return p.db.QueryContext(ctx, query, args...)
+}
+
+func (p *Primary) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	markWritten(ctx)
+	return p.db.QueryRowContext(ctx, query, args...)
+}
+
+func NewPrimary(db *sql.DB) *Primary {
+	return &Primary{
+		db: db,
+	}
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaSet is the read side of the pool. Reads are spread round-robin over
+// the replicas that passed their last health check and fall back to the
+// primary when none did, or when the session has already written.
+type ReplicaSet struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+func (r *ReplicaSet) pick(ctx context.Context) DBTX {
+	if len(r.replicas) == 0 || hasWritten(ctx) {
+		return r.primary
+	}
+
+	start := r.next.Add(1)
+	for i := range uint64(len(r.replicas)) {
+		rep := r.replicas[(start+i)%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+
+	return r.primary
+}
+
+func (r *ReplicaSet) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.pick(ctx).ExecContext(ctx, query, args...)
+}
+
+func (r *ReplicaSet) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.pick(ctx).QueryContext(ctx, query, args...)
+}
+
+func (r *ReplicaSet) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.pick(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (r *ReplicaSet) check(timeout time.Duration) {
+	for _, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		rep.healthy.Store(rep.db.PingContext(ctx) == nil)
+		cancel()
+	}
+}
+
+func (r *ReplicaSet) monitor(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		r.check(interval)
+	}
+}
+
+func NewReplicaSet(primary *sql.DB, replicas []*sql.DB, checkInterval time.Duration) *ReplicaSet {
+	r := &ReplicaSet{
+		primary: primary,
+	}
+	for _, db := range replicas {
+		r.replicas = append(r.replicas, &replica{db: db})
+	}
+
+	if len(r.replicas) > 0 {
+		r.check(checkInterval)
+		go r.monitor(checkInterval)
+	}
+
+	return r
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"time"
+)
+
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, review *domain.Review) error
+	GetReviewForUser(ctx context.Context, movieId, userId int64) (*domain.Review, error)
+	GetReviews(ctx context.Context, queryString *dto.QueryReview) ([]*domain.Review, dto.Metadata, error)
+	UpdateReview(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	DeleteReviewForUser(ctx context.Context, movieId, userId int64) error
+	WithTx(tx *sql.Tx) ReviewRepository
+}
+
+type reviewRepository struct {
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
+}
+
+func (r *reviewRepository) CreateReview(ctx context.Context, review *domain.Review) error {
+	query := `
+        INSERT INTO reviews (movie_id, user_id, rating, body)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at, version`
+
+	args := []any{review.MovieId, review.UserId, review.Rating, review.Body}
+
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	if err := r.dbWrite.QueryRowContext(ctx, query, args...).Scan(&review.Id, &review.CreatedAt, &review.UpdatedAt, &review.Version); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "reviews_movie_user_key"`:
+			return ErrDuplicateReview
+		default:
+			return contextError(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (r *reviewRepository) GetReviewForUser(ctx context.Context, movieId, userId int64) (*domain.Review, error) {
+	query := `
+        SELECT reviews.id, reviews.created_at, reviews.updated_at, reviews.movie_id, reviews.user_id, users.name, reviews.rating, reviews.body, reviews.version
+        FROM reviews
+        INNER JOIN users ON users.id = reviews.user_id
+        WHERE reviews.movie_id = $1 AND reviews.user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	var review domain.Review
+	if err := r.dbRead.QueryRowContext(ctx, query, movieId, userId).Scan(
+		&review.Id,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.MovieId,
+		&review.UserId,
+		&review.UserName,
+		&review.Rating,
+		&review.Body,
+		&review.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) GetReviews(ctx context.Context, queryString *dto.QueryReview) ([]*domain.Review, dto.Metadata, error) {
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), reviews.id, reviews.created_at, reviews.updated_at, reviews.movie_id, reviews.user_id, users.name, reviews.rating, reviews.body, reviews.version
+        FROM reviews
+        INNER JOIN users ON users.id = reviews.user_id
+        WHERE reviews.movie_id = $1
+        ORDER BY reviews.%s %s, reviews.id ASC
+        LIMIT $2 OFFSET $3`, queryString.Filters.SortColumn(), queryString.Filters.SortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	args := []any{queryString.MovieId, queryString.Filters.Limit(), queryString.Filters.Offset()}
+
+	rows, err := r.dbRead.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	reviews := []*domain.Review{}
+	for rows.Next() {
+		var review domain.Review
+		if err = rows.Scan(
+			&totalRecords,
+			&review.Id,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.MovieId,
+			&review.UserId,
+			&review.UserName,
+			&review.Rating,
+			&review.Body,
+			&review.Version,
+		); err != nil {
+			return nil, dto.Metadata{}, contextError(ctx, err)
+		}
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, dto.Metadata{}, contextError(ctx, err)
+	}
+
+	metadata := dto.CalculateMetadata(totalRecords, queryString.Filters.Page, queryString.Filters.PageSize)
+
+	return reviews, metadata, nil
+}
+
+func (r *reviewRepository) UpdateReview(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	query := `
+        UPDATE reviews
+        SET rating = $1, body = $2, updated_at = NOW(), version = version + 1
+        WHERE id = $3 AND version = $4
+        RETURNING updated_at, version`
+
+	args := []any{review.Rating, review.Body, review.Id, review.Version}
+
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	if err := r.dbWrite.QueryRowContext(ctx, query, args...).Scan(&review.UpdatedAt, &review.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, contextError(ctx, err)
+		}
+	}
+
+	return review, nil
+}
+
+func (r *reviewRepository) DeleteReviewForUser(ctx context.Context, movieId, userId int64) error {
+	query := `DELETE FROM reviews WHERE movie_id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.dbWrite.ExecContext(ctx, query, movieId, userId)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *reviewRepository) WithTx(tx *sql.Tx) ReviewRepository {
+	return &reviewRepository{
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: r.queryTimeout,
+	}
+}
+
+func NewReviewRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) ReviewRepository {
+	return &reviewRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
+	}
+}
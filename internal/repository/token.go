@@ -13,11 +13,13 @@ type TokenRepository interface {
 	InsertToken(ctx context.Context, token *domain.Token) error
 	DeleteAllForUser(ctx context.Context, scope string, userId int64) error
 	GetForToken(ctx context.Context, scope, plainText string) (*domain.User, error)
+	WithTx(tx *sql.Tx) TokenRepository
 }
 
 type tokenRepository struct {
-	dbWrite *sql.DB
-	dbRead  *sql.DB
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
 }
 
 func (t *tokenRepository) InsertToken(ctx context.Context, token *domain.Token) error {
@@ -26,10 +28,10 @@ func (t *tokenRepository) InsertToken(ctx context.Context, token *domain.Token)
         VALUES ($1, $2, $3, $4)`
 
 	args := []any{token.Hash, token.UserId, token.Expiry, token.Scope}
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, t.queryTimeout)
 	defer cancel()
 	_, err := t.dbWrite.ExecContext(ctx, query, args...)
-	return err
+	return contextError(ctx, err)
 }
 
 func (t *tokenRepository) DeleteAllForUser(ctx context.Context, scope string, userId int64) error {
@@ -37,18 +39,18 @@ func (t *tokenRepository) DeleteAllForUser(ctx context.Context, scope string, us
         DELETE FROM tokens 
         WHERE scope = $1 AND user_id = $2`
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, t.queryTimeout)
 	defer cancel()
 
 	_, err := t.dbWrite.ExecContext(ctx, query, scope, userId)
-	return err
+	return contextError(ctx, err)
 }
 
 func (t *tokenRepository) GetForToken(ctx context.Context, tokenScope, tokenPlaintext string) (*domain.User, error) {
 	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
 
 	query := `
-        SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.version
+        SELECT users.id, users.created_at, users.name, users.email, users.pending_email, users.password_hash, users.activated, users.version
         FROM users
         INNER JOIN tokens
         ON users.id = tokens.user_id
@@ -59,7 +61,7 @@ func (t *tokenRepository) GetForToken(ctx context.Context, tokenScope, tokenPlai
 	args := []any{tokenHash[:], tokenScope, time.Now()}
 
 	var user domain.User
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, t.queryTimeout)
 	defer cancel()
 
 	if err := t.dbRead.QueryRowContext(ctx, query, args...).Scan(
@@ -67,6 +69,7 @@ func (t *tokenRepository) GetForToken(ctx context.Context, tokenScope, tokenPlai
 		&user.CreatedAt,
 		&user.Name,
 		&user.Email,
+		&user.PendingEmail,
 		&user.Password.Hash,
 		&user.Activated,
 		&user.Version,
@@ -75,15 +78,24 @@ func (t *tokenRepository) GetForToken(ctx context.Context, tokenScope, tokenPlai
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, contextError(ctx, err)
 		}
 	}
 	return &user, nil
 }
 
-func NewTokenRepository(dbWrite, dbRead *sql.DB) TokenRepository {
+func (t *tokenRepository) WithTx(tx *sql.Tx) TokenRepository {
 	return &tokenRepository{
-		dbWrite: dbWrite,
-		dbRead:  dbRead,
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: t.queryTimeout,
+	}
+}
+
+func NewTokenRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) TokenRepository {
+	return &tokenRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
 	}
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// DBTX is the part of *sql.DB and *sql.Tx the repositories rely on, so the
+// same repository code can run either on a pool or inside a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+}
+
+type txManager struct {
+	db *sql.DB
+}
+
+// WithTx runs fn inside a single transaction, committing when fn returns nil
+// and rolling back on an error or a panic.
+func (t *txManager) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	markWritten(ctx)
+
+	defer func() {
+		if pv := recover(); pv != nil {
+			_ = tx.Rollback()
+			panic(pv)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func NewTxManager(db *sql.DB) TxManager {
+	return &txManager{
+		db: db,
+	}
+}
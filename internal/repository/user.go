@@ -12,17 +12,19 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
+	WithTx(tx *sql.Tx) UserRepository
 }
 
 type userRepository struct {
-	dbWrite *sql.DB
-	dbRead  *sql.DB
+	dbWrite      DBTX
+	dbRead       DBTX
+	queryTimeout time.Duration
 }
 
 func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	query := `INSERT INTO users (name, email, password_hash, activated) VALUES ($1, $2, $3, $4) RETURNING id, created_at, version`
 	args := []any{user.Name, user.Email, user.Password.Hash, user.Activated}
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, u.queryTimeout)
 	defer cancel()
 
 	if err := u.dbWrite.QueryRowContext(ctx, query, args...).Scan(&user.Id, &user.CreatedAt, &user.Version); err != nil {
@@ -30,7 +32,7 @@ func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) erro
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
 			return ErrDuplicateEmail
 		default:
-			return err
+			return contextError(ctx, err)
 		}
 	}
 	return nil
@@ -38,17 +40,18 @@ func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) erro
 
 func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	query := `SELECT id, created_at, name, email, password_hash, activated, version
+	query := `SELECT id, created_at, name, email, pending_email, password_hash, activated, version
 	FROM users
 	WHERE email = $1`
 
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, u.queryTimeout)
 	defer cancel()
 	if err := u.dbRead.QueryRowContext(ctx, query, email).Scan(
 		&user.Id,
 		&user.CreatedAt,
 		&user.Name,
 		&user.Email,
+		&user.PendingEmail,
 		&user.Password.Hash,
 		&user.Activated,
 		&user.Version,
@@ -57,7 +60,7 @@ func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*dom
 		case errors.Is(err, sql.ErrNoRows):
 			return nil, ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, contextError(ctx, err)
 		}
 	}
 	return &user, nil
@@ -66,12 +69,12 @@ func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*dom
 func (u *userRepository) UpdateUser(ctx context.Context, user *domain.User) error {
 	query := `
         UPDATE users 
-        SET name = $1, email = $2, password_hash = $3, activated = $4, version = version + 1
-        WHERE id = $5 AND version = $6
+        SET name = $1, email = $2, pending_email = $3, password_hash = $4, activated = $5, version = version + 1
+        WHERE id = $6 AND version = $7
         RETURNING version`
 
-	args := []any{user.Name, user.Email, user.Password.Hash, user.Activated, user.Id, user.Version}
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	args := []any{user.Name, user.Email, user.PendingEmail, user.Password.Hash, user.Activated, user.Id, user.Version}
+	ctx, cancel := context.WithTimeout(ctx, u.queryTimeout)
 	defer cancel()
 
 	if err := u.dbWrite.QueryRowContext(ctx, query, args...).Scan(&user.Version); err != nil {
@@ -81,16 +84,25 @@ func (u *userRepository) UpdateUser(ctx context.Context, user *domain.User) erro
 		case errors.Is(err, sql.ErrNoRows):
 			return ErrEditConflict
 		default:
-			return err
+			return contextError(ctx, err)
 		}
 	}
 
 	return nil
 }
 
-func NewUserRepository(dbWrite, dbRead *sql.DB) UserRepository {
+func (u *userRepository) WithTx(tx *sql.Tx) UserRepository {
 	return &userRepository{
-		dbWrite: dbWrite,
-		dbRead:  dbRead,
+		dbWrite:      tx,
+		dbRead:       tx,
+		queryTimeout: u.queryTimeout,
+	}
+}
+
+func NewUserRepository(dbWrite, dbRead DBTX, queryTimeout time.Duration) UserRepository {
+	return &userRepository{
+		dbWrite:      dbWrite,
+		dbRead:       dbRead,
+		queryTimeout: queryTimeout,
 	}
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils/storage"
+	"image"
+	"io"
+	"maps"
+	"slices"
+)
+
+type ArtworkService interface {
+	SetArtwork(ctx context.Context, id int64, kind string, r io.Reader) (*domain.Movie, error)
+}
+
+type artworkService struct {
+	movieRepository repository.MovieRepository
+	blobStore       storage.BlobStore
+	similar         *SimilarCache
+}
+
+// SetArtwork stores every variant of an uploaded poster or backdrop and then
+// points the movie at them. Each upload gets keys of its own, so a URL
+// always serves the same bytes; the previous variants are removed once the
+// movie no longer refers to them.
+func (a *artworkService) SetArtwork(ctx context.Context, id int64, kind string, r io.Reader) (*domain.Movie, error) {
+	movie, err := a.movieRepository.GetMovieById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := utils.DecodeImage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := make([]byte, 6)
+	rand.Read(tag)
+
+	variants := domain.ArtworkVariants[kind]
+	artwork := make(domain.Artwork, len(variants))
+	var stored []string
+
+	for _, name := range slices.Sorted(maps.Keys(variants)) {
+		key := fmt.Sprintf("movies/%d/%s-%s-%s.jpg", id, kind, hex.EncodeToString(tag), name)
+		if err = a.putVariant(ctx, key, img, variants[name]); err != nil {
+			a.deleteBlobs(stored)
+			return nil, err
+		}
+		stored = append(stored, key)
+		artwork[name] = a.blobStore.URL(key)
+	}
+
+	if err = a.movieRepository.SetArtwork(ctx, id, kind, artwork); err != nil {
+		a.deleteBlobs(stored)
+		return nil, err
+	}
+
+	// Rankings the movie is in carry its artwork URLs, and the previous
+	// ones are about to stop resolving.
+	a.similar.invalidate(id)
+
+	previous := movie.Poster
+	if kind == domain.ArtworkBackdrop {
+		previous, movie.Backdrop = movie.Backdrop, artwork
+	} else {
+		movie.Poster = artwork
+	}
+
+	var old []string
+	for _, url := range previous {
+		if key, ok := a.blobStore.Key(url); ok {
+			old = append(old, key)
+		}
+	}
+	a.deleteBlobs(old)
+
+	return movie, nil
+}
+
+func (a *artworkService) putVariant(ctx context.Context, key string, img *image.RGBA, width int) error {
+	data, err := utils.EncodeJPEG(utils.ResizeImage(img, width))
+	if err != nil {
+		return err
+	}
+	return a.blobStore.Put(ctx, key, "image/jpeg", data)
+}
+
+// deleteBlobs is best effort: a file left behind is only wasted space, so
+// it must not fail a request that has otherwise succeeded.
+func (a *artworkService) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		_ = a.blobStore.Delete(context.Background(), key)
+	}
+}
+
+func NewArtworkService(movieRepository repository.MovieRepository, blobStore storage.BlobStore, similar *SimilarCache) ArtworkService {
+	return &artworkService{
+		movieRepository: movieRepository,
+		blobStore:       blobStore,
+		similar:         similar,
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+)
+
+type CreditService interface {
+	CreateCredit(ctx context.Context, movieId int64, input *dto.Credit) (*domain.Credit, error)
+	GetCreditsForMovie(ctx context.Context, movieId int64) ([]*domain.Credit, error)
+	GetFilmography(ctx context.Context, personId int64) ([]*domain.Credit, error)
+	DeleteCredit(ctx context.Context, movieId, creditId int64) error
+}
+
+type creditService struct {
+	creditRepository repository.CreditRepository
+	movieRepository  repository.MovieRepository
+	personRepository repository.PersonRepository
+}
+
+// CreateCredit returns repository.ErrRecordNotFound when the movie does not
+// exist and ErrPersonNotFound when the person does not, so the handler can
+// tell a missing resource apart from a bad payload.
+func (c *creditService) CreateCredit(ctx context.Context, movieId int64, input *dto.Credit) (*domain.Credit, error) {
+	if _, err := c.movieRepository.GetMovieById(ctx, movieId); err != nil {
+		return nil, err
+	}
+
+	person, err := c.personRepository.GetPersonById(ctx, input.PersonId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrPersonNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	credit := &domain.Credit{
+		MovieId:      movieId,
+		PersonId:     person.Id,
+		Role:         input.Role,
+		Character:    input.Character,
+		BillingOrder: input.BillingOrder,
+		PersonName:   person.Name,
+	}
+
+	if err = c.creditRepository.CreateCredit(ctx, credit); err != nil {
+		return nil, err
+	}
+
+	return credit, nil
+}
+
+func (c *creditService) GetCreditsForMovie(ctx context.Context, movieId int64) ([]*domain.Credit, error) {
+	if _, err := c.movieRepository.GetMovieById(ctx, movieId); err != nil {
+		return nil, err
+	}
+	return c.creditRepository.GetCreditsForMovie(ctx, movieId)
+}
+
+func (c *creditService) GetFilmography(ctx context.Context, personId int64) ([]*domain.Credit, error) {
+	if _, err := c.personRepository.GetPersonById(ctx, personId); err != nil {
+		return nil, err
+	}
+	return c.creditRepository.GetCreditsForPerson(ctx, personId)
+}
+
+func (c *creditService) DeleteCredit(ctx context.Context, movieId, creditId int64) error {
+	return c.creditRepository.DeleteCredit(ctx, movieId, creditId)
+}
+
+func NewCreditService(creditRepository repository.CreditRepository, movieRepository repository.MovieRepository, personRepository repository.PersonRepository) CreditService {
+	return &creditService{
+		creditRepository: creditRepository,
+		movieRepository:  movieRepository,
+		personRepository: personRepository,
+	}
+}
@@ -0,0 +1,11 @@
+package service
+
+import "errors"
+
+var (
+	ErrPasswordMismatch   = errors.New("password mismatch")
+	ErrPersonNotFound     = errors.New("person not found")
+	ErrMovieNotFound      = errors.New("movie not found")
+	ErrBuiltInList        = errors.New("built-in list")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
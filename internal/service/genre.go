@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type GenreService interface {
+	CreateGenre(ctx context.Context, input *dto.Genre) (*domain.Genre, error)
+	GetGenreById(ctx context.Context, id int64) (*domain.Genre, error)
+	GetGenres(ctx context.Context) ([]*domain.Genre, error)
+	UpdateGenre(ctx context.Context, id int64, input *dto.UpdateGenre) (*domain.Genre, error)
+	DeleteGenre(ctx context.Context, id int64) error
+	MergeGenre(ctx context.Context, id, sourceId int64) (*domain.Genre, error)
+	Catalogue(ctx context.Context) (*domain.GenreCatalogue, error)
+}
+
+type genreService struct {
+	txManager       repository.TxManager
+	genreRepository repository.GenreRepository
+
+	// Renaming or merging a genre rewrites movies' genres, which similar
+	// movie rankings both score on and carry.
+	similar *SimilarCache
+
+	// The catalogue is read on every movie write, so it is kept in memory
+	// and reloaded after a local change or once it is older than ttl (which
+	// covers changes made by other instances).
+	mu        sync.Mutex
+	catalogue *domain.GenreCatalogue
+	loadedAt  time.Time
+	ttl       time.Duration
+}
+
+func (g *genreService) CreateGenre(ctx context.Context, input *dto.Genre) (*domain.Genre, error) {
+	genre := &domain.Genre{
+		Slug:    input.Slug,
+		Name:    input.Name,
+		Aliases: input.Aliases,
+	}
+	if genre.Aliases == nil {
+		genre.Aliases = []string{}
+	}
+
+	err := g.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		genreRepository := g.genreRepository.WithTx(tx)
+
+		if err := genreRepository.LockCatalogue(ctx); err != nil {
+			return err
+		}
+
+		if err := checkNames(ctx, genreRepository, genre); err != nil {
+			return err
+		}
+
+		return genreRepository.CreateGenre(ctx, genre)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.invalidate()
+	return genre, nil
+}
+
+func (g *genreService) GetGenreById(ctx context.Context, id int64) (*domain.Genre, error) {
+	return g.genreRepository.GetGenreById(ctx, id)
+}
+
+func (g *genreService) GetGenres(ctx context.Context) ([]*domain.Genre, error) {
+	return g.genreRepository.GetGenres(ctx)
+}
+
+func (g *genreService) UpdateGenre(ctx context.Context, id int64, input *dto.UpdateGenre) (*domain.Genre, error) {
+	var genre *domain.Genre
+	var renamed bool
+
+	err := g.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		genreRepository := g.genreRepository.WithTx(tx)
+
+		if err := genreRepository.LockCatalogue(ctx); err != nil {
+			return err
+		}
+
+		current, err := genreRepository.GetGenreById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		oldSlug := current.Slug
+
+		if input.Slug != nil {
+			current.Slug = *input.Slug
+		}
+
+		if input.Name != nil {
+			current.Name = *input.Name
+		}
+
+		if input.Aliases != nil {
+			current.Aliases = input.Aliases
+		}
+
+		if err = checkNames(ctx, genreRepository, current); err != nil {
+			return err
+		}
+
+		if genre, err = genreRepository.UpdateGenre(ctx, current); err != nil {
+			return err
+		}
+
+		if genre.Slug != oldSlug {
+			renamed = true
+			return genreRepository.RenameOnMovies(ctx, oldSlug, genre.Slug)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.invalidate()
+	if renamed {
+		g.similar.clear()
+	}
+	return genre, nil
+}
+
+func (g *genreService) DeleteGenre(ctx context.Context, id int64) error {
+	genre, err := g.GetGenreById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	inUse, err := g.genreRepository.InUse(ctx, genre.Slug)
+	if err != nil {
+		return err
+	}
+
+	if inUse {
+		return repository.ErrGenreInUse
+	}
+
+	if err := g.genreRepository.DeleteGenre(ctx, id); err != nil {
+		return err
+	}
+
+	g.invalidate()
+	return nil
+}
+
+// MergeGenre folds the source genre into genre id: the source's slug, name
+// and aliases become aliases of id, the movies carrying the source are moved
+// over to id and the source is deleted. Both genres are locked throughout.
+func (g *genreService) MergeGenre(ctx context.Context, id, sourceId int64) (*domain.Genre, error) {
+	var genre *domain.Genre
+
+	err := g.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		genreRepository := g.genreRepository.WithTx(tx)
+
+		if err := genreRepository.LockCatalogue(ctx); err != nil {
+			return err
+		}
+
+		if err := genreRepository.LockGenres(ctx, id, sourceId); err != nil {
+			return err
+		}
+
+		target, err := genreRepository.GetGenreById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		source, err := genreRepository.GetGenreById(ctx, sourceId)
+		if err != nil {
+			return err
+		}
+
+		target.Aliases = mergeAliases(target, source)
+
+		if err = genreRepository.DeleteGenre(ctx, source.Id); err != nil {
+			return err
+		}
+
+		if genre, err = genreRepository.UpdateGenre(ctx, target); err != nil {
+			return err
+		}
+
+		return genreRepository.RenameOnMovies(ctx, source.Slug, target.Slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.invalidate()
+	g.similar.clear()
+	return genre, nil
+}
+
+// mergeAliases returns target's aliases followed by every name source
+// answers to, skipping any that target already answers to.
+func mergeAliases(target, source *domain.Genre) []string {
+	seen := map[string]bool{
+		target.Slug:                  true,
+		strings.ToLower(target.Name): true,
+	}
+
+	aliases := []string{}
+	for _, alias := range slices.Concat(target.Aliases, []string{source.Slug, source.Name}, source.Aliases) {
+		if key := strings.ToLower(alias); !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+func (g *genreService) Catalogue(ctx context.Context) (*domain.GenreCatalogue, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.catalogue != nil && time.Since(g.loadedAt) < g.ttl {
+		return g.catalogue, nil
+	}
+
+	genres, err := g.genreRepository.GetGenres(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.catalogue = domain.NewGenreCatalogue(genres)
+	g.loadedAt = time.Now()
+	return g.catalogue, nil
+}
+
+func (g *genreService) invalidate() {
+	g.mu.Lock()
+	g.catalogue = nil
+	g.mu.Unlock()
+}
+
+// checkNames makes sure none of the names a genre answers to already
+// resolves to a different genre, which would make the catalogue ambiguous.
+// It runs in the writing transaction once the catalogue is locked.
+func checkNames(ctx context.Context, genreRepository repository.GenreRepository, genre *domain.Genre) error {
+	genres, err := genreRepository.GetGenres(ctx)
+	if err != nil {
+		return err
+	}
+
+	var others []*domain.Genre
+	for _, other := range genres {
+		if other.Id != genre.Id {
+			others = append(others, other)
+		}
+	}
+
+	catalogue := domain.NewGenreCatalogue(others)
+	for _, name := range append([]string{genre.Slug, genre.Name}, genre.Aliases...) {
+		if _, taken := catalogue.Resolve(name); taken {
+			return repository.ErrDuplicateGenre
+		}
+	}
+	return nil
+}
+
+func NewGenreService(txManager repository.TxManager, genreRepository repository.GenreRepository, ttl time.Duration, similar *SimilarCache) GenreService {
+	return &genreService{
+		txManager:       txManager,
+		genreRepository: genreRepository,
+		similar:         similar,
+		ttl:             ttl,
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"time"
+)
+
+type ListService interface {
+	CreateList(ctx context.Context, user *domain.User, input *dto.List) (*domain.List, error)
+	GetLists(ctx context.Context, user *domain.User) ([]*domain.List, error)
+	GetList(ctx context.Context, user *domain.User, id int64) (*domain.List, error)
+	GetVisibleList(ctx context.Context, user *domain.User, id int64) (*domain.List, error)
+	UpdateList(ctx context.Context, user *domain.User, id int64, input *dto.UpdateList) (*domain.List, error)
+	DeleteList(ctx context.Context, user *domain.User, id int64) error
+	GetListItems(ctx context.Context, queryString *dto.QueryListItems) ([]*domain.ListItem, dto.Metadata, error)
+	AddListItem(ctx context.Context, user *domain.User, listId int64, input *dto.ListItem) (*domain.ListItem, error)
+	UpdateListItem(ctx context.Context, user *domain.User, listId, movieId int64, input *dto.UpdateListItem) (*domain.ListItem, error)
+	RemoveListItem(ctx context.Context, user *domain.User, listId, movieId int64) error
+}
+
+type listService struct {
+	txManager       repository.TxManager
+	listRepository  repository.ListRepository
+	movieRepository repository.MovieRepository
+}
+
+// ownList fetches a list the user owns. Somebody else's list is reported as
+// missing rather than forbidden, so list ids can't be probed.
+func ownList(ctx context.Context, listRepository repository.ListRepository, user *domain.User, id int64) (*domain.List, error) {
+	list, err := listRepository.GetListById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.UserId != user.Id {
+		return nil, repository.ErrRecordNotFound
+	}
+	return list, nil
+}
+
+func (l *listService) CreateList(ctx context.Context, user *domain.User, input *dto.List) (*domain.List, error) {
+	list := &domain.List{
+		UserId: user.Id,
+		Kind:   domain.ListCustom,
+		Name:   input.Name,
+		Public: input.Public,
+	}
+
+	err := l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockLists(ctx, user.Id); err != nil {
+			return err
+		}
+		return listRepository.CreateList(ctx, list)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *listService) GetLists(ctx context.Context, user *domain.User) ([]*domain.List, error) {
+	return l.listRepository.GetListsForUser(ctx, user.Id)
+}
+
+func (l *listService) GetList(ctx context.Context, user *domain.User, id int64) (*domain.List, error) {
+	return ownList(ctx, l.listRepository, user, id)
+}
+
+func (l *listService) GetVisibleList(ctx context.Context, user *domain.User, id int64) (*domain.List, error) {
+	list, err := l.listRepository.GetListById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !list.Public && list.UserId != user.Id {
+		return nil, repository.ErrRecordNotFound
+	}
+	return list, nil
+}
+
+func (l *listService) UpdateList(ctx context.Context, user *domain.User, id int64, input *dto.UpdateList) (*domain.List, error) {
+	var list *domain.List
+
+	err := l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockLists(ctx, user.Id); err != nil {
+			return err
+		}
+
+		var err error
+		if list, err = ownList(ctx, listRepository, user, id); err != nil {
+			return err
+		}
+
+		if input.Name != nil && *input.Name != list.Name {
+			if list.IsBuiltIn() {
+				return ErrBuiltInList
+			}
+			list.Name = *input.Name
+		}
+
+		if input.Public != nil {
+			list.Public = *input.Public
+		}
+
+		if _, err = listRepository.UpdateList(ctx, list); err != nil {
+			return err
+		}
+
+		if input.Position != nil && *input.Position != list.Position {
+			if err = listRepository.MoveList(ctx, list, *input.Position); err != nil {
+				return err
+			}
+			list, err = listRepository.GetListById(ctx, id)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *listService) DeleteList(ctx context.Context, user *domain.User, id int64) error {
+	return l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockLists(ctx, user.Id); err != nil {
+			return err
+		}
+
+		list, err := ownList(ctx, listRepository, user, id)
+		if err != nil {
+			return err
+		}
+
+		if list.IsBuiltIn() {
+			return ErrBuiltInList
+		}
+
+		return listRepository.DeleteList(ctx, list)
+	})
+}
+
+func (l *listService) GetListItems(ctx context.Context, queryString *dto.QueryListItems) ([]*domain.ListItem, dto.Metadata, error) {
+	return l.listRepository.GetItems(ctx, queryString)
+}
+
+func (l *listService) AddListItem(ctx context.Context, user *domain.User, listId int64, input *dto.ListItem) (*domain.ListItem, error) {
+	movie, err := l.movieRepository.GetMovieById(ctx, input.MovieId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrMovieNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	item := &domain.ListItem{
+		Position: input.Position,
+		Movie:    movie,
+	}
+
+	err = l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockList(ctx, listId); err != nil {
+			return err
+		}
+
+		list, err := ownList(ctx, listRepository, user, listId)
+		if err != nil {
+			return err
+		}
+
+		if list.Kind == domain.ListWatched {
+			item.WatchedAt = input.WatchedAt
+			if item.WatchedAt == nil {
+				now := time.Now().Truncate(time.Second)
+				item.WatchedAt = &now
+			}
+		}
+
+		return listRepository.AddItem(ctx, listId, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (l *listService) UpdateListItem(ctx context.Context, user *domain.User, listId, movieId int64, input *dto.UpdateListItem) (*domain.ListItem, error) {
+	var item *domain.ListItem
+
+	err := l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockList(ctx, listId); err != nil {
+			return err
+		}
+
+		list, err := ownList(ctx, listRepository, user, listId)
+		if err != nil {
+			return err
+		}
+
+		if input.Position != nil {
+			if err = listRepository.MoveItem(ctx, listId, movieId, *input.Position); err != nil {
+				return err
+			}
+		}
+
+		if input.WatchedAt != nil && list.Kind == domain.ListWatched {
+			if err = listRepository.SetWatchedAt(ctx, listId, movieId, *input.WatchedAt); err != nil {
+				return err
+			}
+		}
+
+		item, err = listRepository.GetItem(ctx, listId, movieId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (l *listService) RemoveListItem(ctx context.Context, user *domain.User, listId, movieId int64) error {
+	return l.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		listRepository := l.listRepository.WithTx(tx)
+
+		if err := listRepository.LockList(ctx, listId); err != nil {
+			return err
+		}
+
+		if _, err := ownList(ctx, listRepository, user, listId); err != nil {
+			return err
+		}
+
+		return listRepository.RemoveItem(ctx, listId, movieId)
+	})
+}
+
+func NewListService(txManager repository.TxManager, listRepository repository.ListRepository, movieRepository repository.MovieRepository) ListService {
+	return &listService{
+		txManager:       txManager,
+		listRepository:  listRepository,
+		movieRepository: movieRepository,
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
@@ -9,14 +11,30 @@ import (
 
 type MovieService interface {
 	CreateMovie(ctx context.Context, input *dto.Movie) (*domain.Movie, error)
+	CreateMovies(ctx context.Context, inputs []*dto.Movie, atomic bool) ([]*domain.Movie, map[int]error, error)
 	GetMovieById(ctx context.Context, id int64) (*domain.Movie, error)
+	GetMovieByTmdbId(ctx context.Context, tmdbId int64) (*domain.Movie, error)
 	GetMovies(ctx context.Context, queryString *dto.QueryMovie) ([]*domain.Movie, dto.Metadata, error)
-	UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie) (*domain.Movie, error)
-	DeleteMovie(ctx context.Context, id int64) error
+	SuggestMovies(ctx context.Context, input *dto.SuggestMovie) ([]*domain.MovieSuggestion, error)
+	GetMovieFacets(ctx context.Context, queryString *dto.QueryMovie) (domain.Facets, error)
+	ExportMovies(ctx context.Context, queryString *dto.QueryMovie, fn func(*domain.Movie) error) error
+	GetSimilarMovies(ctx context.Context, id int64, filters dto.Filters) ([]*domain.SimilarMovie, dto.Metadata, error)
+	UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie, version int32) (*domain.Movie, error)
+	PatchMovie(ctx context.Context, id int64, version int32, patch func(movie *domain.Movie) (*dto.UpdateMovie, error)) (*domain.Movie, error)
+	DeleteMovie(ctx context.Context, id int64, version int32) error
 }
 
+// bulkBatchSize is how many movies CreateMovies inserts per statement.
+const bulkBatchSize = 500
+
+// errRollback makes a transaction roll back without anything having failed.
+var errRollback = errors.New("rollback")
+
 type movieService struct {
+	txManager       repository.TxManager
 	movieRepository repository.MovieRepository
+	listRepository  repository.ListRepository
+	similar         *SimilarCache
 }
 
 func (m *movieService) CreateMovie(ctx context.Context, input *dto.Movie) (*domain.Movie, error) {
@@ -25,59 +43,296 @@ func (m *movieService) CreateMovie(ctx context.Context, input *dto.Movie) (*doma
 		Year:    input.Year,
 		Runtime: input.Runtime,
 		Genres:  input.Genres,
+		ImdbId:  input.ImdbId,
+		TmdbId:  input.TmdbId,
 	}
 
 	if err := m.movieRepository.CreateMovie(ctx, movie); err != nil {
 		return nil, err
 	}
 
+	m.similar.clear()
 	return movie, nil
 }
 
+// CreateMovies inserts validated movies in batches of bulkBatchSize within
+// one transaction. A movie whose IMDb or TMDB id is already taken, by an
+// existing movie or an earlier one in inputs, is left out and its index is
+// reported with ErrDuplicateImdbId or ErrDuplicateTmdbId. The returned slice
+// lines up with inputs and is nil where a movie was left out. When atomic is
+// set, a single conflict rolls everything back and no movies are returned.
+func (m *movieService) CreateMovies(ctx context.Context, inputs []*dto.Movie, atomic bool) ([]*domain.Movie, map[int]error, error) {
+	movies := make([]*domain.Movie, len(inputs))
+	conflicts := make(map[int]error)
+
+	err := m.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := m.movieRepository.WithTx(tx)
+		takenImdb := make(map[string]bool)
+		takenTmdb := make(map[int64]bool)
+
+		for start := 0; start < len(inputs); start += bulkBatchSize {
+			batch := inputs[start:min(start+bulkBatchSize, len(inputs))]
+
+			var imdbIds []string
+			var tmdbIds []int64
+			for _, input := range batch {
+				if input.ImdbId != "" {
+					imdbIds = append(imdbIds, input.ImdbId)
+				}
+				if input.TmdbId != 0 {
+					tmdbIds = append(tmdbIds, input.TmdbId)
+				}
+			}
+
+			if len(imdbIds) > 0 || len(tmdbIds) > 0 {
+				existingImdb, existingTmdb, err := movieRepository.GetTakenExternalIds(ctx, imdbIds, tmdbIds)
+				if err != nil {
+					return err
+				}
+				for _, imdbId := range existingImdb {
+					takenImdb[imdbId] = true
+				}
+				for _, tmdbId := range existingTmdb {
+					takenTmdb[tmdbId] = true
+				}
+			}
+
+			var insert []*domain.Movie
+			for j, input := range batch {
+				switch {
+				case input.ImdbId != "" && takenImdb[input.ImdbId]:
+					conflicts[start+j] = repository.ErrDuplicateImdbId
+					continue
+				case input.TmdbId != 0 && takenTmdb[input.TmdbId]:
+					conflicts[start+j] = repository.ErrDuplicateTmdbId
+					continue
+				}
+
+				if input.ImdbId != "" {
+					takenImdb[input.ImdbId] = true
+				}
+				if input.TmdbId != 0 {
+					takenTmdb[input.TmdbId] = true
+				}
+
+				movies[start+j] = &domain.Movie{
+					Title:   input.Title,
+					Year:    input.Year,
+					Runtime: input.Runtime,
+					Genres:  input.Genres,
+					ImdbId:  input.ImdbId,
+					TmdbId:  input.TmdbId,
+				}
+				insert = append(insert, movies[start+j])
+			}
+
+			// Once an atomic import is bound to fail, the remaining batches
+			// are only checked for conflicts so all of them get reported.
+			if atomic && len(conflicts) > 0 {
+				continue
+			}
+
+			if err := movieRepository.CreateMovies(ctx, insert); err != nil {
+				return err
+			}
+		}
+
+		if atomic && len(conflicts) > 0 {
+			return errRollback
+		}
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errRollback):
+		return nil, conflicts, nil
+	case err != nil:
+		return nil, nil, err
+	}
+
+	m.similar.clear()
+	return movies, conflicts, nil
+}
+
 func (m *movieService) GetMovieById(ctx context.Context, id int64) (*domain.Movie, error) {
 	return m.movieRepository.GetMovieById(ctx, id)
 }
 
+func (m *movieService) GetMovieByTmdbId(ctx context.Context, tmdbId int64) (*domain.Movie, error) {
+	return m.movieRepository.GetMovieByTmdbId(ctx, tmdbId)
+}
+
 func (m *movieService) GetMovies(ctx context.Context, queryString *dto.QueryMovie) ([]*domain.Movie, dto.Metadata, error) {
 	return m.movieRepository.GetMovies(ctx, queryString)
 }
 
-func (m *movieService) UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie) (*domain.Movie, error) {
-	movie, err := m.GetMovieById(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+func (m *movieService) GetMovieFacets(ctx context.Context, queryString *dto.QueryMovie) (domain.Facets, error) {
+	return m.movieRepository.GetMovieFacets(ctx, queryString)
+}
 
-	if input.Title != nil {
-		movie.Title = *input.Title
-	}
+func (m *movieService) ExportMovies(ctx context.Context, queryString *dto.QueryMovie, fn func(*domain.Movie) error) error {
+	return m.movieRepository.ExportMovies(ctx, queryString, fn)
+}
 
-	if input.Year != nil {
-		movie.Year = *input.Year
-	}
+func (m *movieService) SuggestMovies(ctx context.Context, input *dto.SuggestMovie) ([]*domain.MovieSuggestion, error) {
+	return m.movieRepository.SuggestMovies(ctx, input)
+}
+
+// GetSimilarMovies serves a page of the movie's similar movies. The ranking
+// is computed once per movie and kept in the similar cache. It is computed on
+// the primary: the cache is cleared by writes, and a lagging replica would
+// otherwise get the ranking from before the write cached for the full ttl.
+func (m *movieService) GetSimilarMovies(ctx context.Context, id int64, filters dto.Filters) ([]*domain.SimilarMovie, dto.Metadata, error) {
+	similar, generation, ok := m.similar.get(id)
+	if !ok {
+		err := m.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+			movieRepository := m.movieRepository.WithTx(tx)
 
-	if input.Runtime != nil {
-		movie.Runtime = *input.Runtime
+			if _, err := movieRepository.GetMovieById(ctx, id); err != nil {
+				return err
+			}
+
+			var err error
+			similar, err = movieRepository.GetSimilarMovies(ctx, id, similarCandidates)
+			return err
+		})
+		if err != nil {
+			return nil, dto.Metadata{}, err
+		}
+		m.similar.put(id, similar, generation)
 	}
 
-	if input.Genres != nil {
-		movie.Genres = input.Genres
+	if filters.UseCursor {
+		return similarByCursor(similar, filters)
 	}
 
-	updatedMovie, err := m.movieRepository.UpdateMovie(ctx, movie)
+	start := min(filters.Offset(), len(similar))
+	end := min(start+filters.Limit(), len(similar))
+
+	return similar[start:end], dto.CalculateMetadata(len(similar), filters.Page, filters.PageSize), nil
+}
+
+// UpdateMovie applies input to the movie. With a version (from If-Match) the
+// update only goes through while the movie is still at that version, and
+// ErrPreconditionFailed is returned otherwise.
+func (m *movieService) UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie, version int32) (*domain.Movie, error) {
+	return m.PatchMovie(ctx, id, version, func(*domain.Movie) (*dto.UpdateMovie, error) {
+		return input, nil
+	})
+}
+
+// PatchMovie is UpdateMovie for changes that depend on the movie as it is,
+// such as a JSON Patch: patch is given the current movie and returns the
+// update to apply, or an error that is returned as it is. The movie is locked
+// and read from the primary, so neither the version checked against If-Match
+// nor what patch sees can be stale.
+func (m *movieService) PatchMovie(ctx context.Context, id int64, version int32, patch func(movie *domain.Movie) (*dto.UpdateMovie, error)) (*domain.Movie, error) {
+	var updatedMovie *domain.Movie
+
+	err := m.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := m.movieRepository.WithTx(tx)
+
+		movie, err := lockMovieVersion(ctx, movieRepository, id, version)
+		if err != nil {
+			return err
+		}
+
+		current := *movie
+		input, err := patch(&current)
+		if err != nil {
+			return err
+		}
+
+		if input.Title != nil {
+			movie.Title = *input.Title
+		}
+
+		if input.Year != nil {
+			movie.Year = *input.Year
+		}
+
+		if input.Runtime != nil {
+			movie.Runtime = *input.Runtime
+		}
+
+		if input.Genres != nil {
+			movie.Genres = input.Genres
+		}
+
+		if input.ImdbId != nil {
+			movie.ImdbId = *input.ImdbId
+		}
+
+		if input.TmdbId != nil {
+			movie.TmdbId = *input.TmdbId
+		}
+
+		updatedMovie, err = movieRepository.UpdateMovie(ctx, movie)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	m.similar.clear()
+
 	return updatedMovie, nil
 }
 
-func (m *movieService) DeleteMovie(ctx context.Context, id int64) error {
-	return m.movieRepository.DeleteMovie(ctx, id)
+// DeleteMovie takes the movie off every list before deleting it, so the
+// lists it was on keep their positions gapless. The movie is locked first,
+// so a version (from If-Match) is checked against what is really deleted;
+// a mismatch returns ErrPreconditionFailed.
+func (m *movieService) DeleteMovie(ctx context.Context, id int64, version int32) error {
+	err := m.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := m.movieRepository.WithTx(tx)
+
+		movie, err := lockMovieVersion(ctx, movieRepository, id, version)
+		if err != nil {
+			return err
+		}
+
+		if err = m.listRepository.WithTx(tx).RemoveMovieFromLists(ctx, id); err != nil {
+			return err
+		}
+		return movieRepository.DeleteMovie(ctx, id, movie.Version)
+	})
+	if err != nil {
+		return err
+	}
+
+	m.similar.invalidate(id)
+	return nil
+}
+
+// lockMovieVersion locks the movie and reads it back from the primary. With
+// a version (from If-Match) it returns ErrPreconditionFailed unless the movie
+// is at that version, including when it no longer exists: RFC 9110 answers
+// an If-Match naming a representation that isn't current with 412, not 404.
+func lockMovieVersion(ctx context.Context, movieRepository repository.MovieRepository, id int64, version int32) (*domain.Movie, error) {
+	if err := movieRepository.LockMovie(ctx, id); err != nil {
+		if version != 0 && errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, ErrPreconditionFailed
+		}
+		return nil, err
+	}
+
+	movie, err := movieRepository.GetMovieById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && movie.Version != version {
+		return nil, ErrPreconditionFailed
+	}
+	return movie, nil
 }
 
-func NewMovieService(movieRepository repository.MovieRepository) MovieService {
+func NewMovieService(txManager repository.TxManager, movieRepository repository.MovieRepository, listRepository repository.ListRepository, similar *SimilarCache) MovieService {
 	return &movieService{
+		txManager:       txManager,
 		movieRepository: movieRepository,
+		listRepository:  listRepository,
+		similar:         similar,
 	}
 }
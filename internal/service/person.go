@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+)
+
+type PersonService interface {
+	CreatePerson(ctx context.Context, input *dto.Person) (*domain.Person, error)
+	GetPersonById(ctx context.Context, id int64) (*domain.Person, error)
+	GetPeople(ctx context.Context, queryString *dto.QueryPerson) ([]*domain.Person, dto.Metadata, error)
+	UpdatePerson(ctx context.Context, id int64, input *dto.UpdatePerson) (*domain.Person, error)
+	DeletePerson(ctx context.Context, id int64) error
+}
+
+type personService struct {
+	personRepository repository.PersonRepository
+}
+
+func (p *personService) CreatePerson(ctx context.Context, input *dto.Person) (*domain.Person, error) {
+	person := &domain.Person{
+		Name:      input.Name,
+		BirthYear: input.BirthYear,
+		Biography: input.Biography,
+	}
+
+	if err := p.personRepository.CreatePerson(ctx, person); err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+func (p *personService) GetPersonById(ctx context.Context, id int64) (*domain.Person, error) {
+	return p.personRepository.GetPersonById(ctx, id)
+}
+
+func (p *personService) GetPeople(ctx context.Context, queryString *dto.QueryPerson) ([]*domain.Person, dto.Metadata, error) {
+	return p.personRepository.GetPeople(ctx, queryString)
+}
+
+func (p *personService) UpdatePerson(ctx context.Context, id int64, input *dto.UpdatePerson) (*domain.Person, error) {
+	person, err := p.GetPersonById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		person.Name = *input.Name
+	}
+
+	if input.BirthYear != nil {
+		person.BirthYear = *input.BirthYear
+	}
+
+	if input.Biography != nil {
+		person.Biography = *input.Biography
+	}
+
+	return p.personRepository.UpdatePerson(ctx, person)
+}
+
+func (p *personService) DeletePerson(ctx context.Context, id int64) error {
+	return p.personRepository.DeletePerson(ctx, id)
+}
+
+func NewPersonService(personRepository repository.PersonRepository) PersonService {
+	return &personService{
+		personRepository: personRepository,
+	}
+}
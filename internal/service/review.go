@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+)
+
+type ReviewService interface {
+	CreateReview(ctx context.Context, user *domain.User, movieId int64, input *dto.Review) (*domain.Review, error)
+	GetReviews(ctx context.Context, queryString *dto.QueryReview) ([]*domain.Review, dto.Metadata, error)
+	UpdateReview(ctx context.Context, user *domain.User, movieId int64, input *dto.Review) (*domain.Review, error)
+	DeleteReview(ctx context.Context, user *domain.User, movieId int64) error
+}
+
+// reviewService keeps the movie's rating in step with its reviews: every
+// write locks the movie, changes the review and recomputes the rating in
+// one transaction. Reviews also feed the co-rating part of every similar
+// movie ranking, so each write clears the similar cache.
+type reviewService struct {
+	txManager        repository.TxManager
+	reviewRepository repository.ReviewRepository
+	movieRepository  repository.MovieRepository
+	similar          *SimilarCache
+}
+
+func (r *reviewService) CreateReview(ctx context.Context, user *domain.User, movieId int64, input *dto.Review) (*domain.Review, error) {
+	review := &domain.Review{
+		MovieId:  movieId,
+		UserId:   user.Id,
+		UserName: user.Name,
+		Rating:   input.Rating,
+		Body:     input.Body,
+	}
+
+	err := r.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := r.movieRepository.WithTx(tx)
+
+		if err := movieRepository.LockMovie(ctx, movieId); err != nil {
+			return err
+		}
+
+		if err := r.reviewRepository.WithTx(tx).CreateReview(ctx, review); err != nil {
+			return err
+		}
+
+		return movieRepository.RefreshRating(ctx, movieId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.similar.clear()
+	return review, nil
+}
+
+func (r *reviewService) GetReviews(ctx context.Context, queryString *dto.QueryReview) ([]*domain.Review, dto.Metadata, error) {
+	if _, err := r.movieRepository.GetMovieById(ctx, queryString.MovieId); err != nil {
+		return nil, dto.Metadata{}, err
+	}
+	return r.reviewRepository.GetReviews(ctx, queryString)
+}
+
+func (r *reviewService) UpdateReview(ctx context.Context, user *domain.User, movieId int64, input *dto.Review) (*domain.Review, error) {
+	var review *domain.Review
+
+	err := r.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := r.movieRepository.WithTx(tx)
+		reviewRepository := r.reviewRepository.WithTx(tx)
+
+		if err := movieRepository.LockMovie(ctx, movieId); err != nil {
+			return err
+		}
+
+		var err error
+		review, err = reviewRepository.GetReviewForUser(ctx, movieId, user.Id)
+		if err != nil {
+			return err
+		}
+
+		review.Rating = input.Rating
+		review.Body = input.Body
+
+		if _, err = reviewRepository.UpdateReview(ctx, review); err != nil {
+			return err
+		}
+
+		return movieRepository.RefreshRating(ctx, movieId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.similar.clear()
+	return review, nil
+}
+
+func (r *reviewService) DeleteReview(ctx context.Context, user *domain.User, movieId int64) error {
+	err := r.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		movieRepository := r.movieRepository.WithTx(tx)
+
+		if err := movieRepository.LockMovie(ctx, movieId); err != nil {
+			return err
+		}
+
+		if err := r.reviewRepository.WithTx(tx).DeleteReviewForUser(ctx, movieId, user.Id); err != nil {
+			return err
+		}
+
+		return movieRepository.RefreshRating(ctx, movieId)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.similar.clear()
+	return nil
+}
+
+func NewReviewService(txManager repository.TxManager, reviewRepository repository.ReviewRepository, movieRepository repository.MovieRepository, similar *SimilarCache) ReviewService {
+	return &reviewService{
+		txManager:        txManager,
+		reviewRepository: reviewRepository,
+		movieRepository:  movieRepository,
+		similar:          similar,
+	}
+}
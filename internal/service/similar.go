@@ -0,0 +1,156 @@
+package service
+
+import (
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
+	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// similarCandidates is how many similar movies are ranked and cached per
+// movie; pages past it come back empty.
+const similarCandidates = 100
+
+type similarEntry struct {
+	movies   []*domain.SimilarMovie
+	loadedAt time.Time
+}
+
+// SimilarCache holds the ranked similar movies per movie for ttl. Once it
+// holds size movies, expired entries are dropped to make room and, if none
+// have expired, the oldest one is. It is shared by every service whose
+// writes can change a ranking, and each of them invalidates it.
+type SimilarCache struct {
+	mu      sync.Mutex
+	entries map[int64]similarEntry
+	ttl     time.Duration
+	size    int
+
+	// generation moves on with every invalidation, so a ranking computed
+	// before one is not cached after it.
+	generation uint64
+}
+
+// get returns the movie's cached ranking or, on a miss, the generation to
+// hand put once the ranking has been computed.
+func (s *SimilarCache) get(id int64) ([]*domain.SimilarMovie, uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || time.Since(entry.loadedAt) >= s.ttl {
+		return nil, s.generation, false
+	}
+	return entry.movies, s.generation, true
+}
+
+func (s *SimilarCache) put(id int64, movies []*domain.SimilarMovie, generation uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if generation != s.generation {
+		return
+	}
+
+	if _, ok := s.entries[id]; !ok && len(s.entries) >= s.size {
+		s.evict()
+	}
+	s.entries[id] = similarEntry{movies: movies, loadedAt: time.Now()}
+}
+
+func (s *SimilarCache) evict() {
+	var oldestId int64
+	var oldest time.Time
+	for id, entry := range s.entries {
+		if time.Since(entry.loadedAt) >= s.ttl {
+			delete(s.entries, id)
+			continue
+		}
+		if oldest.IsZero() || entry.loadedAt.Before(oldest) {
+			oldestId, oldest = id, entry.loadedAt
+		}
+	}
+
+	if len(s.entries) >= s.size {
+		delete(s.entries, oldestId)
+	}
+}
+
+// invalidate drops the movie's own ranking and every ranking it appears in,
+// so a deleted movie stops being suggested straight away.
+func (s *SimilarCache) invalidate(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	delete(s.entries, id)
+	for other, entry := range s.entries {
+		if slices.ContainsFunc(entry.movies, func(similar *domain.SimilarMovie) bool { return similar.Movie.Id == id }) {
+			delete(s.entries, other)
+		}
+	}
+}
+
+// clear drops every ranking. A new or edited movie, a review or a genre
+// rename may move a movie up or down in any of them, including ones it
+// wasn't in before.
+func (s *SimilarCache) clear() {
+	s.mu.Lock()
+	s.generation++
+	clear(s.entries)
+	s.mu.Unlock()
+}
+
+// similarByCursor pages the ranking with a keyset on (score, id), the way
+// movie listings are paged, so a page still follows on from the previous
+// one after the ranking has been recomputed.
+func similarByCursor(similar []*domain.SimilarMovie, filters dto.Filters) ([]*domain.SimilarMovie, dto.Metadata, error) {
+	metadata := dto.Metadata{PageSize: filters.PageSize}
+	if filters.IncludeTotal {
+		metadata.TotalRecords = len(similar)
+	}
+
+	if filters.Cursor == nil {
+		end := min(filters.Limit(), len(similar))
+		metadata.HasNext = end < len(similar)
+		return similar[:end], metadata, nil
+	}
+
+	score, err := strconv.ParseFloat(filters.Cursor.Value, 64)
+	if err != nil {
+		return nil, dto.Metadata{}, err
+	}
+
+	// The ranking is ordered by score descending and then id ascending;
+	// i is where the cursor's movie is, or would be.
+	id := filters.Cursor.Id
+	i := sort.Search(len(similar), func(i int) bool {
+		s := similar[i]
+		return s.Score < score || (s.Score == score && s.Movie.Id >= id)
+	})
+
+	if filters.Cursor.Before {
+		start := max(i-filters.Limit(), 0)
+		metadata.HasNext, metadata.HasPrev = true, start > 0
+		return similar[start:i], metadata, nil
+	}
+
+	if i < len(similar) && similar[i].Score == score && similar[i].Movie.Id == id {
+		i++
+	}
+
+	end := min(i+filters.Limit(), len(similar))
+	metadata.HasNext, metadata.HasPrev = end < len(similar), true
+	return similar[i:end], metadata, nil
+}
+
+func NewSimilarCache(ttl time.Duration, size int) *SimilarCache {
+	return &SimilarCache{
+		entries: make(map[int64]similarEntry),
+		ttl:     ttl,
+		size:    max(size, 1),
+	}
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"errors"
+	"database/sql"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
@@ -13,9 +13,13 @@ import (
 type TokenService interface {
 	Tokenize(ctx context.Context, userId int64, ttl time.Duration, scope string) (*domain.Token, error)
 	ActivateUser(ctx context.Context, input *dto.ActivateUser) (*domain.User, error)
+	ResetPassword(ctx context.Context, input *dto.ResetPassword) (*domain.User, error)
+	ConfirmEmailChange(ctx context.Context, input *dto.ConfirmEmailChange) (*domain.User, error)
+	DeleteAllForUser(ctx context.Context, scope string, userId int64) error
 }
 
 type tokenService struct {
+	txManager       repository.TxManager
 	tokenRepository repository.TokenRepository
 	userRepository  repository.UserRepository
 }
@@ -28,29 +32,103 @@ func (t *tokenService) Tokenize(ctx context.Context, userId int64, ttl time.Dura
 }
 
 func (t *tokenService) ActivateUser(ctx context.Context, input *dto.ActivateUser) (*domain.User, error) {
-	user, err := t.tokenRepository.GetForToken(ctx, domain.ScopeActivation, input.TokenPlaintext)
+	var user *domain.User
+
+	err := t.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		tokenRepository := t.tokenRepository.WithTx(tx)
+		userRepository := t.userRepository.WithTx(tx)
+
+		var err error
+		user, err = tokenRepository.GetForToken(ctx, domain.ScopeActivation, input.TokenPlaintext)
+		if err != nil {
+			return err
+		}
+
+		user.Activated = true
+
+		if err := userRepository.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return tokenRepository.DeleteAllForUser(ctx, domain.ScopeActivation, user.Id)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	user.Activated = true
+	return user, nil
+}
+
+func (t *tokenService) ResetPassword(ctx context.Context, input *dto.ResetPassword) (*domain.User, error) {
+	var user *domain.User
+
+	err := t.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		tokenRepository := t.tokenRepository.WithTx(tx)
+		userRepository := t.userRepository.WithTx(tx)
+
+		var err error
+		user, err = tokenRepository.GetForToken(ctx, domain.ScopePasswordReset, input.TokenPlaintext)
+		if err != nil {
+			return err
+		}
+
+		if err := user.Password.Set(input.Password); err != nil {
+			return err
+		}
 
-	if err := t.userRepository.UpdateUser(ctx, user); err != nil {
-		if errors.Is(err, repository.ErrEditConflict) {
-			return nil, repository.ErrEditConflict
+		if err := userRepository.UpdateUser(ctx, user); err != nil {
+			return err
 		}
+
+		return tokenRepository.DeleteAllForUser(ctx, domain.ScopePasswordReset, user.Id)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := t.tokenRepository.DeleteAllForUser(ctx, domain.ScopeActivation, user.Id); err != nil {
+	return user, nil
+}
+
+func (t *tokenService) ConfirmEmailChange(ctx context.Context, input *dto.ConfirmEmailChange) (*domain.User, error) {
+	var user *domain.User
+
+	err := t.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		tokenRepository := t.tokenRepository.WithTx(tx)
+		userRepository := t.userRepository.WithTx(tx)
+
+		var err error
+		user, err = tokenRepository.GetForToken(ctx, domain.ScopeEmailChange, input.TokenPlaintext)
+		if err != nil {
+			return err
+		}
+
+		if user.PendingEmail == nil {
+			return repository.ErrRecordNotFound
+		}
+
+		user.Email = *user.PendingEmail
+		user.PendingEmail = nil
+
+		if err := userRepository.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return tokenRepository.DeleteAllForUser(ctx, domain.ScopeEmailChange, user.Id)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func NewTokenService(tokenRepository repository.TokenRepository, userRepository repository.UserRepository) TokenService {
+func (t *tokenService) DeleteAllForUser(ctx context.Context, scope string, userId int64) error {
+	return t.tokenRepository.DeleteAllForUser(ctx, scope, userId)
+}
+
+func NewTokenService(txManager repository.TxManager, tokenRepository repository.TokenRepository, userRepository repository.UserRepository) TokenService {
 	return &tokenService{
+		txManager:       txManager,
 		tokenRepository: tokenRepository,
 		userRepository:  userRepository,
 	}
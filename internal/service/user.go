@@ -2,44 +2,109 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/domain"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/dto"
 	"github.com/saleh-ghazimoradi/FilmFetch/internal/repository"
+	"github.com/saleh-ghazimoradi/FilmFetch/utils"
+	"time"
 )
 
 type UserService interface {
-	CreateUser(ctx context.Context, input *dto.User) (*domain.User, error)
+	CreateUser(ctx context.Context, input *dto.User) (*domain.User, *domain.Token, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
-	UpdateUser(ctx context.Context, user *dto.User) error
+	UpdateUser(ctx context.Context, user *domain.User, input *dto.UpdateUser) (*domain.User, error)
 }
 
 type userService struct {
-	userRepository repository.UserRepository
+	txManager            repository.TxManager
+	userRepository       repository.UserRepository
+	tokenRepository      repository.TokenRepository
+	permissionRepository repository.PermissionRepository
+	listRepository       repository.ListRepository
 }
 
-func (u *userService) CreateUser(ctx context.Context, input *dto.User) (*domain.User, error) {
+func (u *userService) CreateUser(ctx context.Context, input *dto.User) (*domain.User, *domain.Token, error) {
 	us := domain.User{}
 	us.Name = input.Name
 	us.Email = input.Email
 	if err := us.Password.Set(input.Password); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if err := u.userRepository.CreateUser(ctx, &us); err != nil {
-		return nil, err
+
+	var token *domain.Token
+
+	err := u.txManager.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := u.userRepository.WithTx(tx).CreateUser(ctx, &us); err != nil {
+			return err
+		}
+		if err := u.permissionRepository.WithTx(tx).AddForUser(ctx, us.Id, domain.PermissionMoviesRead); err != nil {
+			return err
+		}
+		if err := u.listRepository.WithTx(tx).CreateDefaultLists(ctx, us.Id); err != nil {
+			return err
+		}
+		token = utils.GenerateToken(us.Id, 3*24*time.Hour, domain.ScopeActivation)
+		return u.tokenRepository.WithTx(tx).InsertToken(ctx, token)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return &us, nil
+
+	return &us, token, nil
 }
 
 func (u *userService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	return u.userRepository.GetUserByEmail(ctx, email)
 }
 
-func (u *userService) UpdateUser(ctx context.Context, user *dto.User) error {
-	return nil
+func (u *userService) UpdateUser(ctx context.Context, user *domain.User, input *dto.UpdateUser) (*domain.User, error) {
+	if input.Name != nil {
+		user.Name = *input.Name
+	}
+
+	// A new email address is only parked in pending_email here; it replaces
+	// the current one once the owner confirms it through ConfirmEmailChange.
+	if input.Email != nil && *input.Email != user.Email {
+		existing, err := u.userRepository.GetUserByEmail(ctx, *input.Email)
+		switch {
+		case err == nil && existing.Id != user.Id:
+			return nil, repository.ErrDuplicateEmail
+		case err != nil && !errors.Is(err, repository.ErrRecordNotFound):
+			return nil, err
+		}
+		user.PendingEmail = input.Email
+	}
+
+	if input.Password != nil {
+		match, err := user.Password.Matches(*input.CurrentPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		if !match {
+			return nil, ErrPasswordMismatch
+		}
+
+		if err := user.Password.Set(*input.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
+func NewUserService(txManager repository.TxManager, userRepository repository.UserRepository, tokenRepository repository.TokenRepository, permissionRepository repository.PermissionRepository, listRepository repository.ListRepository) UserService {
 	return &userService{
-		userRepository: userRepository,
+		txManager:            txManager,
+		userRepository:       userRepository,
+		tokenRepository:      tokenRepository,
+		permissionRepository: permissionRepository,
+		listRepository:       listRepository,
 	}
 }
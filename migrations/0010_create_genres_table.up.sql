@@ -0,0 +1,94 @@
+CREATE TABLE IF NOT EXISTS genres (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    slug text NOT NULL UNIQUE,
+    name text NOT NULL,
+    aliases text[] NOT NULL DEFAULT '{}',
+    version integer NOT NULL DEFAULT 1
+);
+
+-- Every free-form genre already on a movie becomes a slug: lower case with
+-- runs of anything but letters and digits collapsed into a single dash.
+CREATE OR REPLACE FUNCTION pg_temp.plain_slug(value text) RETURNS text AS $$
+    SELECT trim(BOTH '-' FROM regexp_replace(lower(trim(value)), '[^a-z0-9]+', '-', 'g'))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Well-known spellings of the same genre, keyed by the slug they would
+-- otherwise get, so that "Sci-Fi" and "Science Fiction" end up as one genre.
+-- They are also kept as aliases of the genre they map to.
+CREATE OR REPLACE FUNCTION pg_temp.genre_synonyms() RETURNS TABLE (slug text, canonical text) AS $$
+    VALUES ('sci-fi', 'science-fiction'),
+           ('scifi', 'science-fiction'),
+           ('sf', 'science-fiction'),
+           ('animated', 'animation'),
+           ('cartoon', 'animation'),
+           ('biopic', 'biography'),
+           ('bio', 'biography'),
+           ('doc', 'documentary'),
+           ('docu', 'documentary'),
+           ('documentaries', 'documentary'),
+           ('noir', 'film-noir'),
+           ('historical', 'history'),
+           ('musicals', 'musical'),
+           ('romantic', 'romance'),
+           ('sports', 'sport'),
+           ('comedies', 'comedy'),
+           ('dramas', 'drama'),
+           ('thrillers', 'thriller'),
+           ('westerns', 'western'),
+           ('war-film', 'war')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION pg_temp.genre_slug(value text) RETURNS text AS $$
+    SELECT coalesce(
+        (SELECT canonical FROM pg_temp.genre_synonyms() AS synonyms WHERE synonyms.slug = pg_temp.plain_slug(value)),
+        pg_temp.plain_slug(value)
+    )
+$$ LANGUAGE sql IMMUTABLE;
+
+-- A genre is named after a spelling that slugifies to it directly ("Science
+-- Fiction" rather than "Sci-Fi") when there is one; every other spelling is
+-- kept as an alias.
+WITH spellings AS (
+    SELECT DISTINCT pg_temp.genre_slug(genre) AS slug, genre
+    FROM movies, unnest(genres) AS genre
+    WHERE pg_temp.genre_slug(genre) <> ''
+), names AS (
+    SELECT slug, coalesce(min(genre) FILTER (WHERE pg_temp.plain_slug(genre) = slug), min(genre)) AS name
+    FROM spellings
+    GROUP BY slug
+)
+INSERT INTO genres (slug, name, aliases)
+SELECT names.slug,
+       names.name,
+       ARRAY(
+           SELECT DISTINCT ON (lower(alias)) alias
+           FROM (
+               SELECT spellings.genre FROM spellings WHERE spellings.slug = names.slug
+               UNION ALL
+               SELECT synonyms.slug FROM pg_temp.genre_synonyms() AS synonyms WHERE synonyms.canonical = names.slug
+           ) AS candidates(alias)
+           WHERE lower(alias) NOT IN (names.slug, lower(names.name))
+           ORDER BY lower(alias), alias
+       )
+FROM names
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE movies
+SET genres = normalized.genres
+FROM (
+    SELECT movies.id, array_agg(slugs.slug ORDER BY slugs.ord) AS genres
+    FROM movies
+    CROSS JOIN LATERAL (
+        SELECT pg_temp.genre_slug(genre) AS slug, min(ord) AS ord
+        FROM unnest(movies.genres) WITH ORDINALITY AS g(genre, ord)
+        WHERE pg_temp.genre_slug(genre) <> ''
+        GROUP BY 1
+    ) AS slugs
+    GROUP BY movies.id
+) AS normalized
+WHERE movies.id = normalized.id AND movies.genres IS DISTINCT FROM normalized.genres;
+
+INSERT INTO permissions (code)
+VALUES ('genres:write')
+ON CONFLICT (code) DO NOTHING;
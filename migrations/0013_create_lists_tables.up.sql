@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS lists (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    kind text NOT NULL DEFAULT 'custom',
+    name text NOT NULL,
+    public boolean NOT NULL DEFAULT false,
+    position integer NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    CONSTRAINT lists_kind_check CHECK (kind IN ('watchlist', 'watched', 'custom')),
+    CONSTRAINT lists_user_name_key UNIQUE (user_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS lists_user_kind_idx ON lists (user_id, kind) WHERE kind <> 'custom';
+CREATE INDEX IF NOT EXISTS lists_user_position_idx ON lists (user_id, position);
+
+CREATE TABLE IF NOT EXISTS list_items (
+    list_id bigint NOT NULL REFERENCES lists ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    position integer NOT NULL,
+    added_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    watched_at timestamp(0) with time zone,
+    PRIMARY KEY (list_id, movie_id)
+);
+
+CREATE INDEX IF NOT EXISTS list_items_position_idx ON list_items (list_id, position, movie_id);
+CREATE INDEX IF NOT EXISTS list_items_movie_id_idx ON list_items (movie_id);
+
+INSERT INTO lists (user_id, kind, name, position)
+SELECT id, 'watchlist', 'Watchlist', 1 FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO lists (user_id, kind, name, position)
+SELECT id, 'watched', 'Watched', 2 FROM users
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS reviews_user_id_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS reviews_user_id_idx ON reviews (user_id);
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	minImageSide   = 100
+	maxImageSide   = 10_000
+	maxImagePixels = 40_000_000
+)
+
+var ErrInvalidImage = errors.New("invalid image")
+
+// imageFormats maps the content types accepted for upload to the format
+// name the image package reports for them.
+var imageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// DecodeImage reads a JPEG, PNG or WebP image. The type is taken from the
+// bytes themselves, never from what the client claims, and the dimensions
+// are checked from the header before any pixels are decoded. Errors about
+// the image itself wrap ErrInvalidImage. The image is returned flattened
+// onto white, ready to be resized into every variant.
+func DecodeImage(r io.Reader) (*image.RGBA, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	format, ok := imageFormats[http.DetectContentType(data)]
+	if !ok {
+		return nil, fmt.Errorf("%w: must be a JPEG, PNG or WebP image", ErrInvalidImage)
+	}
+
+	config, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decoded != format {
+		return nil, fmt.Errorf("%w: could not be read as %s", ErrInvalidImage, format)
+	}
+
+	switch {
+	case config.Width < minImageSide || config.Height < minImageSide:
+		return nil, fmt.Errorf("%w: must be at least %dx%d pixels", ErrInvalidImage, minImageSide, minImageSide)
+	case config.Width > maxImageSide || config.Height > maxImageSide || config.Width*config.Height > maxImagePixels:
+		return nil, fmt.Errorf("%w: must not be larger than %dx%d pixels", ErrInvalidImage, maxImageSide, maxImageSide)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not be read as %s", ErrInvalidImage, format)
+	}
+	return flatten(img), nil
+}
+
+// flatten draws img onto a white background, as transparency is lost once
+// the image is stored as a JPEG.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// ResizeImage scales src down to width, keeping its aspect ratio, by
+// averaging the source pixels that fall under each target pixel. Images no
+// wider than width are returned as they are, so src must not be modified
+// afterwards.
+func ResizeImage(src *image.RGBA, width int) *image.RGBA {
+	bounds := src.Bounds()
+
+	if width >= bounds.Dx() {
+		return src
+	}
+
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0, y1 := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := range width {
+			x0, x1 := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y+sy):]
+				for sx := x0; sx < x1; sx++ {
+					r += int(row[sx*4])
+					g += int(row[sx*4+1])
+					b += int(row[sx*4+2])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG re-encodes img from its pixels alone, so nothing from the
+// uploaded file's metadata (EXIF, GPS position, comments) survives.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
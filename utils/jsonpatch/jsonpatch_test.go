@@ -0,0 +1,342 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// normalize re-encodes a JSON value, so documents can be compared regardless
+// of member order and whitespace.
+func normalize(t *testing.T, data string) string {
+	t.Helper()
+
+	value, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("Decode(%s) returned error: %v", data, err)
+	}
+
+	js, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	return string(js)
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		// RFC 6902, Appendix A.
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:  "A.8 testing a value: success",
+			doc:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:    "A.9 testing a value: error",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:    "A.12 adding to a nonexistent target",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			// The duplicate "op" leaves a remove of a missing member, which
+			// fails, so the patch as a whole is still rejected.
+			name:    "A.13 invalid JSON patch document",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:    "A.15 comparing strings and numbers",
+			doc:     `{"/": 9, "~1": 10}`,
+			patch:   `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+
+		// Edge cases.
+		{
+			name:  "escaped slash and tilde in member names",
+			doc:   `{"a/b": 1, "m~n": 2}`,
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 3}, {"op": "remove", "path": "/m~0n"}]`,
+			want:  `{"a/b": 3}`,
+		},
+		{
+			name:    "index with a leading zero",
+			doc:     `{"foo": ["bar", "baz"]}`,
+			patch:   `[{"op": "add", "path": "/foo/01", "value": "qux"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "index past the end",
+			doc:     `{"foo": ["bar"]}`,
+			patch:   `[{"op": "add", "path": "/foo/2", "value": "qux"}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:  "index at the end",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux"]}`,
+		},
+		{
+			name:    "- before the last token",
+			doc:     `{"foo": [{"bar": 1}]}`,
+			patch:   `[{"op": "add", "path": "/foo/-/bar", "value": 2}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "removing -",
+			doc:     `{"foo": ["bar"]}`,
+			patch:   `[{"op": "remove", "path": "/foo/-"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "moving a value into its own child",
+			doc:     `{"foo": {"bar": 1}}`,
+			patch:   `[{"op": "move", "from": "/foo", "path": "/foo/baz"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:  "moving a value onto itself",
+			doc:   `{"foo": {"bar": 1}}`,
+			patch: `[{"op": "move", "from": "/foo", "path": "/foo"}]`,
+			want:  `{"foo": {"bar": 1}}`,
+		},
+		{
+			name:  "copying leaves the source alone",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "add", "path": "/baz/-", "value": "qux"}]`,
+			want:  `{"foo": ["bar"], "baz": ["bar", "qux"]}`,
+		},
+		{
+			name:  "numbers compare by value",
+			doc:   `{"a": 1, "b": [100], "c": {"d": 0.5}}`,
+			patch: `[{"op": "test", "path": "/a", "value": 1.0}, {"op": "test", "path": "/b", "value": [1e2]}, {"op": "test", "path": "/c", "value": {"d": 5E-1}}]`,
+			want:  `{"a": 1, "b": [100], "c": {"d": 0.5}}`,
+		},
+		{
+			name:    "different numbers",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "test", "path": "/a", "value": 1.000000000000000000001}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "test of a missing member",
+			doc:     `{"a": null}`,
+			patch:   `[{"op": "test", "path": "/b", "value": null}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:  "test of a null member",
+			doc:   `{"a": null}`,
+			patch: `[{"op": "test", "path": "/a", "value": null}]`,
+			want:  `{"a": null}`,
+		},
+		{
+			name:  "replacing the whole document",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "", "value": ["baz"]}]`,
+			want:  `["baz"]`,
+		},
+		{
+			name:    "replacing a missing member",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "replace", "path": "/baz", "value": 1}]`,
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "missing value",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "missing path",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "remove"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "path without a leading slash",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "remove", "path": "foo"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unknown operation",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "merge", "path": "/foo", "value": 1}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "not an array of operations",
+			doc:     `{"foo": "bar"}`,
+			patch:   `{"op": "remove", "path": "/foo"}`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Decode([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Decode(%s) returned error: %v", tt.doc, err)
+			}
+
+			got, err := Patch(doc, []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Patch error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Patch returned error: %v", err)
+			}
+
+			js, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal returned error: %v", err)
+			}
+			if want := normalize(t, tt.want); string(js) != want {
+				t.Errorf("Patch = %s, want %s", js, want)
+			}
+		})
+	}
+}
+
+// TestMergePatch runs the examples of RFC 7396, Appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{doc: `{"a": "b"}`, patch: `{"a": "c"}`, want: `{"a": "c"}`},
+		{doc: `{"a": "b"}`, patch: `{"b": "c"}`, want: `{"a": "b", "b": "c"}`},
+		{doc: `{"a": "b"}`, patch: `{"a": null}`, want: `{}`},
+		{doc: `{"a": "b", "b": "c"}`, patch: `{"a": null}`, want: `{"b": "c"}`},
+		{doc: `{"a": ["b"]}`, patch: `{"a": "c"}`, want: `{"a": "c"}`},
+		{doc: `{"a": "c"}`, patch: `{"a": ["b"]}`, want: `{"a": ["b"]}`},
+		{doc: `{"a": {"b": "c"}}`, patch: `{"a": {"b": "d", "c": null}}`, want: `{"a": {"b": "d"}}`},
+		{doc: `{"a": [{"b": "c"}]}`, patch: `{"a": [1]}`, want: `{"a": [1]}`},
+		{doc: `["a", "b"]`, patch: `["c", "d"]`, want: `["c", "d"]`},
+		{doc: `{"a": "b"}`, patch: `["c"]`, want: `["c"]`},
+		{doc: `{"a": "foo"}`, patch: `null`, want: `null`},
+		{doc: `{"a": "foo"}`, patch: `"bar"`, want: `"bar"`},
+		{doc: `{"e": null}`, patch: `{"a": 1}`, want: `{"e": null, "a": 1}`},
+		{doc: `[1, 2]`, patch: `{"a": "b", "c": null}`, want: `{"a": "b"}`},
+		{doc: `{}`, patch: `{"a": {"bb": {"ccc": null}}}`, want: `{"a": {"bb": {}}}`},
+		// The example from section 3.
+		{
+			doc:   `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`,
+			patch: `{"title": "Hello!", "phoneNumber": "+01-555-1234", "author": {"familyName": null}, "tags": ["example"]}`,
+			want:  `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-555-1234"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		doc, err := Decode([]byte(tt.doc))
+		if err != nil {
+			t.Fatalf("Decode(%s) returned error: %v", tt.doc, err)
+		}
+
+		got, err := MergePatch(doc, []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) returned error: %v", tt.doc, tt.patch, err)
+			continue
+		}
+
+		js, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal returned error: %v", err)
+		}
+		if want := normalize(t, tt.want); string(js) != want {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, js, want)
+		}
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	for _, patch := range []string{``, `{"a":`, `{"a": 1} {"b": 2}`} {
+		if _, err := MergePatch(map[string]any{}, []byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("MergePatch(%q) error = %v, want ErrInvalidPatch", patch, err)
+		}
+	}
+}
@@ -19,6 +19,7 @@ type Postgresql struct {
 	MaxIdleTime time.Duration
 	SSLMode     string
 	Timeout     time.Duration
+	Replicas    []string
 }
 
 type Options func(*Postgresql)
@@ -83,12 +84,18 @@ func WithTimeout(timeout time.Duration) Options {
 	}
 }
 
+func WithReplicas(dsns ...string) Options {
+	return func(p *Postgresql) {
+		p.Replicas = dsns
+	}
+}
+
 func (p *Postgresql) uri() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", p.Host, p.Port, p.User, p.Password, p.Name, p.SSLMode)
 }
 
-func (p *Postgresql) Connect() (*sql.DB, error) {
-	db, err := sql.Open("postgres", p.uri())
+func (p *Postgresql) open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +103,14 @@ func (p *Postgresql) Connect() (*sql.DB, error) {
 	db.SetMaxOpenConns(p.MaxOpenConn)
 	db.SetMaxIdleConns(p.MaxIdleConn)
 	db.SetConnMaxIdleTime(p.MaxIdleTime)
+	return db, nil
+}
+
+func (p *Postgresql) Connect() (*sql.DB, error) {
+	db, err := p.open(p.uri())
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
 	defer cancel()
@@ -107,6 +122,24 @@ func (p *Postgresql) Connect() (*sql.DB, error) {
 	return db, nil
 }
 
+// ConnectReplicas opens a pool for every replica DSN. Replicas aren't pinged
+// here: one that is down at startup is simply skipped for reads until it
+// passes a health check.
+func (p *Postgresql) ConnectReplicas() ([]*sql.DB, error) {
+	replicas := make([]*sql.DB, 0, len(p.Replicas))
+	for _, dsn := range p.Replicas {
+		db, err := p.open(dsn)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, err
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas, nil
+}
+
 func NewPostgresql(opts ...Options) *Postgresql {
 	p := &Postgresql{}
 	for _, opt := range opts {
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps blobs as files below a root directory. Keys never
+// escape it: every access goes through an os.Root.
+type LocalStore struct {
+	root    *os.Root
+	baseURL string
+}
+
+func (l *LocalStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	if dir := path.Dir(key); dir != "." {
+		if err := l.root.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	// Written to a temporary name and renamed into place, so a reader never
+	// sees half a file.
+	tmp := key + ".tmp"
+	if err := l.root.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return l.root.Rename(tmp, key)
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	if err := l.root.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalStore) URL(key string) string {
+	return l.baseURL + key
+}
+
+func (l *LocalStore) Key(url string) (string, bool) {
+	key, found := strings.CutPrefix(url, l.baseURL)
+	return key, found && validKey(key)
+}
+
+// Handler serves the stored files. Keys are never reused for different
+// content, so responses may be cached for good.
+func (l *LocalStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !validKey(key) {
+			http.NotFound(w, r)
+			return
+		}
+
+		f, err := l.root.Open(key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, key, info.ModTime().Truncate(time.Second), f)
+	})
+}
+
+func validKey(key string) bool {
+	return key != "" && fs.ValidPath(key) && !strings.HasSuffix(key, ".tmp")
+}
+
+func NewLocalStore(dir, baseURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		root:    root,
+		baseURL: baseURL,
+	}, nil
+}
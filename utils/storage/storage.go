@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore keeps uploaded files under slash-separated keys and hands out
+// the URLs they are served from.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	// Key is the reverse of URL. It reports false for URLs the store did
+	// not hand out.
+	Key(url string) (string, bool)
+}